@@ -20,6 +20,7 @@ import (
 	"go.opencensus.io/stats"
 
 	"github.com/ovh/cds/engine/api/action"
+	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/audit"
 	"github.com/ovh/cds/engine/api/authentication"
 	"github.com/ovh/cds/engine/api/authentication/builtin"
@@ -205,6 +206,21 @@ type Configuration struct {
 	Workflow struct {
 		MaxRuns int64 `toml:"maxRuns" comment:"Maximum of runs by workflow" json:"maxRuns" default:"255"`
 	} `toml:"workflow" comment:"######################\n 'Workflow' global configuration \n######################" json:"workflow"`
+	Application struct {
+		MaxVariables                int64   `toml:"maxVariables" comment:"Maximum of variables by application, 0 means unlimited" json:"maxVariables" default:"0"`
+		MaxDescriptionLength        int     `toml:"maxDescriptionLength" comment:"Maximum length of an application description in bytes" json:"maxDescriptionLength" default:"2048"`
+		ExistsCacheTTL              int     `toml:"existsCacheTTL" comment:"Validity in seconds of cached application existence checks, 0 disables the cache" json:"existsCacheTTL" default:"0"`
+		ExistsCacheMaxEntries       int     `toml:"existsCacheMaxEntries" comment:"Maximum number of cached application existence checks" json:"existsCacheMaxEntries" default:"10000"`
+		IDCacheTTL                  int     `toml:"idCacheTTL" comment:"Validity in seconds of cached applications loaded by id, 0 disables the cache" json:"idCacheTTL" default:"0"`
+		IDCacheMaxEntries           int     `toml:"idCacheMaxEntries" comment:"Maximum number of cached applications loaded by id" json:"idCacheMaxEntries" default:"1000"`
+		WarmUpProjectIDs            []int64 `toml:"warmUpProjectIDs" comment:"Ids of the projects which applications are loaded in cache at startup" json:"warmUpProjectIDs"`
+		WarmUpTopProjects           int     `toml:"warmUpTopProjects" comment:"Number of most used projects which applications are loaded in cache at startup" json:"warmUpTopProjects" default:"0"`
+		IdempotencyKeyTTL           int64   `toml:"idempotencyKeyTTL" comment:"Validity in minutes of the idempotency keys used to create applications" json:"idempotencyKeyTTL" default:"1440"`
+		SignatureSelfTestSampleSize int     `toml:"signatureSelfTestSampleSize" comment:"Number of applications which signature is checked at startup, the API will not start if most of them are invalid. 0 disables the check" json:"signatureSelfTestSampleSize" default:"0"`
+		AccessTrackingQueueSize     int     `toml:"accessTrackingQueueSize" comment:"Number of application accesses kept between two writes of their last accessed date, 0 disables access tracking" json:"accessTrackingQueueSize" default:"0"`
+		ChangelogRetentionDays      int64   `toml:"changelogRetentionDays" comment:"Number of days the application changes are kept in the changelog, 0 keeps them forever" json:"changelogRetentionDays" default:"30"`
+		NamePolicy                  string  `toml:"namePolicy" comment:"Regexp that new application names should match, e.g. ^[a-z]+-[a-z0-9-]+$. Empty means no policy" json:"namePolicy"`
+	} `toml:"application" comment:"######################\n 'Application' global configuration \n######################" json:"application"`
 }
 
 // DefaultValues is the struc for API Default configuration default values
@@ -519,6 +535,13 @@ func (a *API) Serve(ctx context.Context) error {
 		return fmt.Errorf("cannot setup database keys: %v", err)
 	}
 
+	if a.Config.Application.SignatureSelfTestSampleSize > 0 {
+		log.Info(ctx, "Checking applications signature...")
+		if err := application.SelfTest(ctx, a.mustDB(), a.Config.Application.SignatureSelfTestSampleSize); err != nil {
+			return fmt.Errorf("applications signature self test failed: %v", err)
+		}
+	}
+
 	// Init dao packages
 	featureflipping.Init(gorpmapping.Mapper)
 
@@ -642,6 +665,58 @@ func (a *API) Serve(ctx context.Context) error {
 		event.DequeueEvent(ctx, a.mustDB())
 	}, a.PanicDump())
 
+	application.SetMaxVariables(a.Config.Application.MaxVariables)
+	if err := application.SetNamePolicy(a.Config.Application.NamePolicy); err != nil {
+		return fmt.Errorf("invalid application name policy: %v", err)
+	}
+	if a.Config.Application.IdempotencyKeyTTL > 0 {
+		application.SetIdempotencyKeyTTL(time.Duration(a.Config.Application.IdempotencyKeyTTL) * time.Minute)
+	}
+	if a.Config.Application.MaxDescriptionLength > 0 {
+		sdk.ApplicationDescriptionMaxLength = a.Config.Application.MaxDescriptionLength
+	}
+	application.SetVCSPasswordAccessSink(func(ctx context.Context, access application.VCSPasswordAccess) {
+		log.Info(ctx, "application> vcs strategy password of application %d accessed by %q at %v", access.ApplicationID, access.Accessor, access.Timestamp)
+	})
+
+	if a.Config.Application.ExistsCacheTTL > 0 {
+		application.EnableExistsCache(time.Duration(a.Config.Application.ExistsCacheTTL)*time.Second, a.Config.Application.ExistsCacheMaxEntries)
+	}
+	if a.Config.Application.IDCacheTTL > 0 {
+		application.EnableIDCache(time.Duration(a.Config.Application.IDCacheTTL)*time.Second, a.Config.Application.IDCacheMaxEntries)
+	}
+	if a.Config.Application.ExistsCacheTTL > 0 || a.Config.Application.IDCacheTTL > 0 {
+		a.GoRoutines.Exec(ctx, "application.WarmCache", func(ctx context.Context) {
+			projectIDs := a.Config.Application.WarmUpProjectIDs
+			if a.Config.Application.WarmUpTopProjects > 0 {
+				ids, err := application.LoadTopProjectIDsByUsage(a.mustDB(), a.Config.Application.WarmUpTopProjects)
+				if err != nil {
+					log.Error(ctx, "application.WarmCache> cannot load top projects: %v", err)
+				}
+				projectIDs = append(projectIDs, ids...)
+			}
+			if err := application.WarmCache(ctx, a.mustDB(), projectIDs); err != nil {
+				log.Error(ctx, "application.WarmCache> %v", err)
+			}
+		}, a.PanicDump())
+	}
+
+	a.GoRoutines.Run(ctx, "application.PurgeIdempotencyKeys", func(ctx context.Context) {
+		application.PurgeIdempotencyKeys(ctx, a.mustDB(), time.Hour)
+	}, a.PanicDump())
+
+	if a.Config.Application.ChangelogRetentionDays > 0 {
+		a.GoRoutines.Run(ctx, "application.PurgeChangelog", func(ctx context.Context) {
+			application.PurgeChangelog(ctx, a.mustDB(), time.Duration(a.Config.Application.ChangelogRetentionDays)*24*time.Hour, time.Hour)
+		}, a.PanicDump())
+	}
+
+	if a.Config.Application.AccessTrackingQueueSize > 0 {
+		a.GoRoutines.Run(ctx, "application.TrackAccess", func(ctx context.Context) {
+			application.TrackAccess(ctx, a.mustDB(), a.Config.Application.AccessTrackingQueueSize, time.Minute)
+		}, a.PanicDump())
+	}
+
 	log.Info(ctx, "Initializing internal routines...")
 	a.GoRoutines.Run(ctx, "maintenance.Subscribe", func(ctx context.Context) {
 		if err := a.listenMaintenance(ctx); err != nil {
@@ -695,6 +770,9 @@ func (a *API) Serve(ctx context.Context) error {
 	migrate.Add(ctx, sdk.Migration{Name: "RunsSecrets", Release: "0.47.0", Blocker: false, Automatic: true, ExecFunc: func(ctx context.Context) error {
 		return migrate.RunsSecrets(ctx, a.DBConnectionFactory.GetDBMap(gorpmapping.Mapper))
 	}})
+	migrate.Add(ctx, sdk.Migration{Name: "ApplicationVariableNameIndex", Release: "0.47.0", Blocker: false, Automatic: true, ExecFunc: func(ctx context.Context) error {
+		return migrate.ApplicationVariableNameIndex(ctx, a.DBConnectionFactory.GetDBMap(gorpmapping.Mapper)())
+	}})
 
 	isFreshInstall, errF := version.IsFreshInstall(a.mustDB())
 	if errF != nil {
@@ -30,6 +30,14 @@ import (
 	"github.com/ovh/cds/sdk/exportentities"
 )
 
+// applicationMaskingPolicy returns the masking policy of applications for the caller.
+func applicationMaskingPolicy(ctx context.Context) application.MaskingPolicy {
+	if isAdmin(ctx) || isService(ctx) {
+		return application.MaskingPolicies.Admin
+	}
+	return application.MaskingPolicies.User
+}
+
 func (api *API) getApplicationsHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		vars := mux.Vars(r)
@@ -74,6 +82,10 @@ func (api *API) getApplicationsHandler() service.Handler {
 		if err != nil {
 			return sdk.WrapError(err, "Cannot load applications from db")
 		}
+		ctx = application.ContextWithMaskingPolicy(ctx, applicationMaskingPolicy(ctx))
+		for i := range applications {
+			application.ApplyMaskingPolicy(ctx, &applications[i])
+		}
 
 		if strings.ToUpper(withPermissions) == "W" {
 			var groupIDs []int64
@@ -142,6 +154,7 @@ func (api *API) getApplicationHandler() service.Handler {
 		if errApp != nil {
 			return sdk.WrapError(errApp, "getApplicationHandler: Cannot load application %s for project %s from db", applicationName, projectKey)
 		}
+		application.ApplyMaskingPolicy(application.ContextWithMaskingPolicy(ctx, applicationMaskingPolicy(ctx)), app)
 
 		if withUsage {
 			usage, errU := loadApplicationUsage(ctx, api.mustDB(), projectKey, applicationName)
@@ -282,6 +295,10 @@ func (api *API) addApplicationHandler() service.Handler {
 			return sdk.WrapError(sdk.ErrInvalidApplicationPattern, "addApplicationHandler: Application name %s do not respect pattern %s", app.Name, sdk.NamePattern)
 		}
 
+		if idemKey := r.Header.Get(sdk.IdempotencyKeyHeader); idemKey != "" {
+			ctx = application.ContextWithIdempotencyKey(ctx, idemKey)
+		}
+
 		tx, err := api.mustDB().Begin()
 		if err != nil {
 			return sdk.WrapError(err, "Cannot start transaction")
@@ -289,14 +306,23 @@ func (api *API) addApplicationHandler() service.Handler {
 
 		defer tx.Rollback() // nolint
 
-		if err := application.Insert(tx, *proj, &app); err != nil {
+		res, err := application.InsertWithResult(ctx, tx, *proj, &app)
+		if err != nil {
 			return sdk.WrapError(err, "Cannot insert pipeline")
 		}
+		if !res.Verified {
+			return sdk.WrapError(sdk.ErrUnknownError, "application %d signature is invalid after insert", res.ID)
+		}
 
 		if err := tx.Commit(); err != nil {
 			return sdk.WithStack(err)
 		}
 
+		// A replayed creation returns the existing application without a new event
+		if res.Replayed {
+			return service.WriteJSON(w, app, http.StatusOK)
+		}
+
 		event.PublishAddApplication(ctx, proj.Key, app, getAPIConsumer(ctx))
 
 		return service.WriteJSON(w, app, http.StatusOK)
@@ -326,7 +352,7 @@ func (api *API) deleteApplicationHandler() service.Handler {
 		}
 		defer tx.Rollback() // nolint
 
-		err = application.DeleteApplication(tx, app.ID)
+		err = application.DeleteApplication(ctx, tx, app.ID)
 		if err != nil {
 			return sdk.WrapError(err, "Cannot delete application")
 		}
@@ -391,7 +417,7 @@ func (api *API) cloneApplicationHandler() service.Handler {
 // cloneApplication Clone an application with all her dependencies: pipelines, permissions, triggers
 func cloneApplication(ctx context.Context, db gorpmapper.SqlExecutorWithTx, store cache.Store, proj sdk.Project, newApp *sdk.Application, appToClone *sdk.Application) error {
 	// Create Application
-	if err := application.Insert(db, proj, newApp); err != nil {
+	if err := application.Insert(ctx, db, proj, newApp); err != nil {
 		return err
 	}
 
@@ -418,7 +444,7 @@ func cloneApplication(ctx context.Context, db gorpmapper.SqlExecutorWithTx, stor
 			return sdk.WithStack(sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid variable type %s", newVar.Type))
 		}
 
-		if err := application.InsertVariable(db, newApp.ID, newVar, getAPIConsumer(ctx)); err != nil {
+		if err := application.InsertVariable(ctx, db, newApp.ID, newVar, getAPIConsumer(ctx)); err != nil {
 			return sdk.WrapError(err, "cloneApplication> Cannot add variable %s in application %s", newVar.Name, newApp.Name)
 		}
 	}
@@ -484,7 +510,7 @@ func (api *API) updateAsCodeApplicationHandler() service.Handler {
 
 		var rootApp *sdk.Application
 		if wkHolder.WorkflowData.Node.Context != nil && wkHolder.WorkflowData.Node.Context.ApplicationID != 0 {
-			rootApp, err = application.LoadByIDWithClearVCSStrategyPassword(tx, wkHolder.WorkflowData.Node.Context.ApplicationID)
+			rootApp, err = application.LoadByIDWithClearVCSStrategyPassword(ctx, tx, wkHolder.WorkflowData.Node.Context.ApplicationID)
 			if err != nil {
 				return err
 			}
@@ -553,7 +579,7 @@ func (api *API) updateApplicationHandler() service.Handler {
 			return sdk.WrapError(err, "cannot load project %s", projectKey)
 		}
 
-		app, err := application.LoadByNameWithClearVCSStrategyPassword(api.mustDB(), projectKey, applicationName, application.LoadOptions.Default)
+		app, err := application.LoadByNameWithClearVCSStrategyPassword(ctx, api.mustDB(), projectKey, applicationName, application.LoadOptions.Default)
 		if err != nil {
 			return sdk.WrapError(err, "cannot load application %s", applicationName)
 		}
@@ -594,7 +620,7 @@ func (api *API) updateApplicationHandler() service.Handler {
 			return sdk.WrapError(err, "Cannot start transaction")
 		}
 		defer tx.Rollback() // nolint
-		if err := application.Update(tx, app); err != nil {
+		if err := application.Update(ctx, tx, app); err != nil {
 			return sdk.WrapError(err, "Cannot delete application %s", applicationName)
 		}
 
@@ -604,6 +630,7 @@ func (api *API) updateApplicationHandler() service.Handler {
 
 		event.PublishUpdateApplication(ctx, p.Key, *app, old, getAPIConsumer(ctx))
 
+		application.ApplyMaskingPolicy(application.ContextWithMaskingPolicy(ctx, applicationMaskingPolicy(ctx)), app)
 		return service.WriteJSON(w, app, http.StatusOK)
 
 	}
@@ -639,7 +666,7 @@ func (api *API) postApplicationMetadataHandler() service.Handler {
 		}
 		defer tx.Rollback() // nolint
 
-		if err := application.Update(tx, app); err != nil {
+		if err := application.Update(ctx, tx, app); err != nil {
 			return sdk.WrapError(err, "unable to update application")
 		}
 
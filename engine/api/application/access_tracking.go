@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// accessTracking holds the queue of accessed applications, disabled until TrackAccess is running.
+var accessTracking = struct {
+	sync.RWMutex
+	queue chan int64
+}{}
+
+// trackAccess queues given application to be marked as accessed. It never blocks, the access is dropped if
+// tracking is disabled or the queue is full.
+func trackAccess(appID int64) {
+	accessTracking.RLock()
+	queue := accessTracking.queue
+	accessTracking.RUnlock()
+	if queue == nil {
+		return
+	}
+	select {
+	case queue <- appID:
+	default:
+	}
+}
+
+// TrackAccess enables LoadOptions.WithAccessTracking and sets last_accessed_at on accessed applications, each
+// flush interval, until given context is done. At most queueSize accesses are kept between two flushes.
+// The column is not part of the signed data.
+func TrackAccess(ctx context.Context, db gorp.SqlExecutor, queueSize int, flushInterval time.Duration) {
+	queue := make(chan int64, queueSize)
+	accessTracking.Lock()
+	accessTracking.queue = queue
+	accessTracking.Unlock()
+	defer func() {
+		accessTracking.Lock()
+		accessTracking.queue = nil
+		accessTracking.Unlock()
+	}()
+
+	tick := time.NewTicker(flushInterval)
+	defer tick.Stop()
+	accessed := make(map[int64]struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-queue:
+			accessed[id] = struct{}{}
+		case <-tick.C:
+			if len(accessed) == 0 {
+				continue
+			}
+			ids := make([]int64, 0, len(accessed))
+			for id := range accessed {
+				ids = append(ids, id)
+			}
+			accessed = make(map[int64]struct{})
+			if _, err := db.Exec("UPDATE application SET last_accessed_at = $2 WHERE id = ANY($1)", pq.Int64Array(ids), time.Now()); err != nil {
+				log.Error(ctx, "application.TrackAccess> cannot set last accessed date for applications %v: %v", ids, err)
+			}
+		}
+	}
+}
+
+// LoadAllUnaccessedSince returns the applications of given project that were not loaded with
+// LoadOptions.WithAccessTracking since given date, sorted by name.
+func LoadAllUnaccessedSince(ctx context.Context, db gorp.SqlExecutor, projectID int64, since time.Time, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	AND (application.last_accessed_at IS NULL OR application.last_accessed_at < $2)
+	ORDER BY application.name ASC`).Args(projectID, since)
+	return getAll(ctx, db, opts, query)
+}
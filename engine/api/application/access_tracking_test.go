@@ -0,0 +1,55 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestTrackAccess(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1"})
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2"})
+
+	start := time.Now()
+
+	// Loading with the option while tracking is disabled does nothing
+	_, err := application.LoadByID(db, app1.ID, application.LoadOptions.WithAccessTracking)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		application.TrackAccess(ctx, db, 10, 50*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Wait for the tracker to be running
+	require.Eventually(t, func() bool {
+		_, err := application.LoadByID(db, app1.ID, application.LoadOptions.WithAccessTracking)
+		require.NoError(t, err)
+		apps, err := application.LoadAllUnaccessedSince(context.TODO(), db, proj.ID, start)
+		require.NoError(t, err)
+		return len(apps) == 1
+	}, 5*time.Second, 100*time.Millisecond)
+
+	apps, err := application.LoadAllUnaccessedSince(context.TODO(), db, proj.ID, start)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, "my-app2", apps[0].Name)
+}
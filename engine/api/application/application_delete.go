@@ -1,6 +1,8 @@
 package application
 
 import (
+	"context"
+
 	"github.com/go-gorp/gorp"
 	"github.com/lib/pq"
 
@@ -8,8 +10,12 @@ import (
 	"github.com/ovh/cds/sdk"
 )
 
-// DeleteApplication Delete the given application
-func DeleteApplication(db gorp.SqlExecutor, applicationID int64) error {
+// DeleteApplication Delete the given application, sdk.ErrForbidden is returned if it is read only.
+func DeleteApplication(ctx context.Context, db gorp.SqlExecutor, applicationID int64) error {
+	if err := CheckWritable(ctx, db, applicationID); err != nil {
+		return err
+	}
+
 	// Delete variables
 	if err := DeleteAllVariables(db, applicationID); err != nil {
 		return err
@@ -20,6 +26,20 @@ func DeleteApplication(db gorp.SqlExecutor, applicationID int64) error {
 		return err
 	}
 
+	if err := invalidateExistsCacheByApplicationID(db, applicationID); err != nil {
+		return err
+	}
+
+	projectID, err := db.SelectInt("SELECT project_id FROM application WHERE id = $1", applicationID)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load application project")
+	}
+	if projectID != 0 {
+		if err := insertChange(db, sdk.ApplicationChangeDelete, applicationID, projectID, nil); err != nil {
+			return err
+		}
+	}
+
 	query := `DELETE FROM application WHERE id=$1`
 	if _, err := db.Exec(query, applicationID); err != nil {
 		if e, ok := err.(*pq.Error); ok {
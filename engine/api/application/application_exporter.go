@@ -1,17 +1,23 @@
 package application
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
 
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/exportentities"
 )
 
 // Export an application
-func Export(db gorp.SqlExecutor, key string, appName string, encryptFunc sdk.EncryptFunc) (exportentities.Application, error) {
-	app, err := LoadByNameWithClearVCSStrategyPassword(db, key, appName,
+func Export(ctx context.Context, db gorp.SqlExecutor, key string, appName string, encryptFunc sdk.EncryptFunc) (exportentities.Application, error) {
+	app, err := LoadByNameWithClearVCSStrategyPassword(ctx, db, key, appName,
 		LoadOptions.WithVariablesWithClearPassword,
 		LoadOptions.WithClearKeys,
 		LoadOptions.WithClearDeploymentStrategies,
@@ -83,3 +89,84 @@ func ExportApplication(db gorp.SqlExecutor, app sdk.Application, encryptFunc sdk
 
 	return exportentities.NewApplication(app, keys)
 }
+
+// exportAllBatchSize is the number of applications loaded at once by ExportAll and ExportAllStream.
+const exportAllBatchSize = 50
+
+// ExportAll exports all the applications of a project ordered by name. Secrets are masked so the result
+// can't be imported back with its secrets. Applications are loaded by batches to limit memory usage.
+func ExportAll(ctx context.Context, db gorp.SqlExecutor, projectID int64) ([]exportentities.Application, error) {
+	var res []exportentities.Application
+	if err := exportAll(ctx, db, projectID, func(e exportentities.Application) error {
+		res = append(res, e)
+		return nil
+	}, nil); err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = []exportentities.Application{}
+	}
+	return res, nil
+}
+
+// ExportAllStream writes the masked exports of all the applications of a project ordered by name to given writer,
+// one json document per line. Only one batch of applications is kept in memory, the output is flushed after each batch.
+func ExportAllStream(ctx context.Context, db gorp.SqlExecutor, projectID int64, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	return exportAll(ctx, db, projectID, func(e exportentities.Application) error {
+		return sdk.WithStack(enc.Encode(e))
+	}, func() error {
+		return sdk.WithStack(bw.Flush())
+	})
+}
+
+// exportAll calls exportFunc for the masked export of each application of a project ordered by name, and flushFunc
+// if not nil after each batch.
+func exportAll(ctx context.Context, db gorp.SqlExecutor, projectID int64, exportFunc func(exportentities.Application) error, flushFunc func() error) error {
+	if err := checkProjectID(projectID); err != nil {
+		return err
+	}
+	var ids []int64
+	if _, err := db.Select(&ids, "SELECT id FROM application WHERE project_id = $1 ORDER BY name ASC", projectID); err != nil {
+		return sdk.WrapError(err, "cannot load applications of project %d", projectID)
+	}
+
+	maskFunc := func(gorp.SqlExecutor, int64, string, string) (string, error) {
+		return sdk.PasswordPlaceholder, nil
+	}
+
+	for i := 0; i < len(ids); i += exportAllBatchSize {
+		if err := ctx.Err(); err != nil {
+			return sdk.WithStack(err)
+		}
+		end := i + exportAllBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		query := gorpmapping.NewQuery(`
+		SELECT application.*
+		FROM application
+		WHERE application.id = ANY($1)
+		ORDER BY application.name ASC`).Args(pq.Int64Array(ids[i:end]))
+		apps, err := getAll(ctx, db, []LoadOptionFunc{LoadOptions.WithVariables, LoadOptions.WithKeys, LoadOptions.WithDeploymentStrategies}, query)
+		if err != nil {
+			return err
+		}
+		for _, app := range apps {
+			e, err := ExportApplication(db, app, maskFunc, "")
+			if err != nil {
+				return sdk.WrapError(err, "cannot export application %s", app.Name)
+			}
+			if err := exportFunc(e); err != nil {
+				return err
+			}
+		}
+		if flushFunc != nil {
+			if err := flushFunc(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package application_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+func TestExportAll(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-app2",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "user",
+			Password:       "my-password",
+		},
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+			{Name: "my-text", Type: sdk.StringVariable, Value: "my-text-value"},
+		},
+	})
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1"})
+
+	res, err := application.ExportAll(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, "my-app1", res[0].Name)
+	require.Equal(t, "my-app2", res[1].Name)
+	require.Equal(t, sdk.PasswordPlaceholder, res[1].VCSPassword)
+	require.Equal(t, sdk.PasswordPlaceholder, res[1].Variables["my-secret"].Value)
+	require.Equal(t, "my-text-value", res[1].Variables["my-text"].Value)
+}
+
+func TestExportAllStream(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-app2",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+		},
+	})
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1"})
+
+	var buf bytes.Buffer
+	require.NoError(t, application.ExportAllStream(context.TODO(), db, proj.ID, &buf))
+
+	var res []exportentities.Application
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e exportentities.Application
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		res = append(res, e)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, res, 2)
+	require.Equal(t, "my-app1", res[0].Name)
+	require.Equal(t, "my-app2", res[1].Name)
+	require.Equal(t, sdk.PasswordPlaceholder, res[1].Variables["my-secret"].Value)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	buf.Reset()
+	require.Error(t, application.ExportAllStream(ctx, db, proj.ID, &buf))
+	require.Empty(t, buf.Bytes())
+}
@@ -43,7 +43,7 @@ func Import(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Proje
 		app.ID = oldApp.ID
 
 		//Save app in database
-		if err := Update(db, app); err != nil {
+		if err := Update(ctx, db, app); err != nil {
 			return sdk.WrapError(err, "Unable to update application")
 		}
 
@@ -52,7 +52,7 @@ func Import(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Proje
 		}
 	} else {
 		//Save application in database
-		if err := Insert(db, proj, app); err != nil {
+		if err := Insert(ctx, db, proj, app); err != nil {
 			return sdk.WrapError(err, "application.Import")
 		}
 
@@ -61,7 +61,7 @@ func Import(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Proje
 		}
 	}
 
-	if err := importVariables(db, app, u, msgChan); err != nil {
+	if err := importVariables(ctx, db, app, u, msgChan); err != nil {
 		return err
 	}
 
@@ -80,7 +80,7 @@ func Import(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Proje
 	//Manage keys
 	for _, k := range app.Keys {
 		k.ApplicationID = app.ID
-		if err := InsertKey(db, &k); err != nil {
+		if err := InsertKey(ctx, db, &k); err != nil {
 			return sdk.WrapError(err, "unable to insert key %s", k.Name)
 		}
 		if msgChan != nil {
@@ -107,14 +107,14 @@ func Import(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Proje
 }
 
 //importVariables is able to create variable on an existing application
-func importVariables(db gorpmapper.SqlExecutorWithTx, app *sdk.Application, u sdk.Identifiable, msgChan chan<- sdk.Message) error {
+func importVariables(ctx context.Context, db gorpmapper.SqlExecutorWithTx, app *sdk.Application, u sdk.Identifiable, msgChan chan<- sdk.Message) error {
 	for i := range app.Variables {
 		newVar := &app.Variables[i]
 		if !sdk.IsInArray(newVar.Type, sdk.AvailableVariableType) {
 			return sdk.WithStack(sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid variable type %s", newVar.Type))
 		}
 
-		if err := InsertVariable(db, app.ID, newVar, u); err != nil {
+		if err := InsertVariable(ctx, db, app.ID, newVar, u); err != nil {
 			return sdk.WrapError(err, "importVariables> Cannot add variable %s in application %s", newVar.Name, app.Name)
 		}
 	}
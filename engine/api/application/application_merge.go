@@ -0,0 +1,238 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+// MergeConflictStrategy defines what to do when a variable or a key exists on both merged applications.
+type MergeConflictStrategy string
+
+// Available merge conflict strategies.
+const (
+	// MergeConflictKeep keeps the value of the kept application.
+	MergeConflictKeep MergeConflictStrategy = "keep"
+	// MergeConflictOverwrite replaces the value of the kept application by the value of the merged one.
+	MergeConflictOverwrite MergeConflictStrategy = "overwrite"
+	// MergeConflictError aborts the merge.
+	MergeConflictError MergeConflictStrategy = "error"
+)
+
+// IsValid returns an error if the strategy is unknown.
+func (s MergeConflictStrategy) IsValid() error {
+	switch s {
+	case MergeConflictKeep, MergeConflictOverwrite, MergeConflictError:
+		return nil
+	}
+	return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid merge conflict strategy %q", s)
+}
+
+// Merge moves workflow node contexts, variables, keys, deployment strategies, additional repositories and webhooks
+// of application mergeID to application keepID then deletes application mergeID. Both applications should belong to
+// the same project and be writable, a frozen application can't be merged into another one. Variables are matched
+// case-insensitively. Given db should be a transaction so nothing is written if the merge fails.
+func Merge(ctx context.Context, db gorpmapper.SqlExecutorWithTx, keepID, mergeID int64, strategy MergeConflictStrategy, u sdk.Identifiable) error {
+	if err := strategy.IsValid(); err != nil {
+		return err
+	}
+	if keepID == mergeID {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot merge application %d with itself", keepID)
+	}
+	if err := LockApplications(ctx, db, []int64{keepID, mergeID}); err != nil {
+		return err
+	}
+
+	keepApp, err := loadByIDWithClearVCSStrategyPassword(ctx, db, keepID, LoadOptions.WithVariablesWithClearPassword, LoadOptions.WithClearKeys)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load application %d", keepID)
+	}
+	mergeApp, err := loadByIDWithClearVCSStrategyPassword(ctx, db, mergeID, LoadOptions.WithVariablesWithClearPassword, LoadOptions.WithClearKeys)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load application %d", mergeID)
+	}
+	if keepApp.ProjectID != mergeApp.ProjectID {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot merge applications from different projects")
+	}
+	for _, id := range []int64{keepID, mergeID} {
+		if err := CheckWritable(ctx, db, id); err != nil {
+			return err
+		}
+	}
+	frozen, err := LoadFrozen(db, mergeID)
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return sdk.NewErrorFrom(sdk.ErrForbidden, "application %d is frozen", mergeID)
+	}
+
+	if _, err := db.Exec("UPDATE w_node_context SET application_id = $1 WHERE application_id = $2", keepID, mergeID); err != nil {
+		return sdk.WrapError(err, "cannot move workflow node contexts from application %d to %d", mergeID, keepID)
+	}
+
+	if err := mergeVariables(ctx, db, keepApp, mergeApp, strategy, u); err != nil {
+		return err
+	}
+
+	if err := mergeKeys(ctx, db, keepApp, mergeApp, strategy); err != nil {
+		return err
+	}
+
+	if err := mergeDeploymentStrategies(ctx, db, keepID, mergeID, strategy); err != nil {
+		return err
+	}
+
+	if err := mergeRepositories(ctx, db, keepID, mergeID); err != nil {
+		return err
+	}
+
+	if err := mergeWebhooks(ctx, db, keepID, mergeID, strategy); err != nil {
+		return err
+	}
+
+	if err := DeleteApplication(ctx, db, mergeID); err != nil {
+		return err
+	}
+
+	// Update the kept application so its last modification date, content hash and changelog reflect the merge
+	keepApp.Variables = nil
+	keepApp.Keys = nil
+	return Update(ctx, db, keepApp)
+}
+
+func mergeVariables(ctx context.Context, db gorpmapper.SqlExecutorWithTx, keepApp, mergeApp *sdk.Application, strategy MergeConflictStrategy, u sdk.Identifiable) error {
+	existing := make(map[string]sdk.ApplicationVariable, len(keepApp.Variables))
+	for _, v := range keepApp.Variables {
+		existing[strings.ToLower(v.Name)] = v
+	}
+
+	for _, v := range mergeApp.Variables {
+		old, has := existing[strings.ToLower(v.Name)]
+		if !has {
+			newVar := sdk.ApplicationVariable{Name: v.Name, Type: v.Type, Value: v.Value}
+			if err := InsertVariable(ctx, db, keepApp.ID, &newVar, u); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch strategy {
+		case MergeConflictError:
+			return sdk.NewErrorFrom(sdk.ErrVariableExists, "variable %s exists in both applications", v.Name)
+		case MergeConflictOverwrite:
+			updated := old
+			updated.Type = v.Type
+			updated.Value = v.Value
+			if err := UpdateVariable(ctx, db, keepApp.ID, &updated, &old, u); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func mergeKeys(ctx context.Context, db gorpmapper.SqlExecutorWithTx, keepApp, mergeApp *sdk.Application, strategy MergeConflictStrategy) error {
+	existing := make(map[string]struct{}, len(keepApp.Keys))
+	for _, k := range keepApp.Keys {
+		existing[k.Name] = struct{}{}
+	}
+
+	for _, k := range mergeApp.Keys {
+		if _, has := existing[k.Name]; has {
+			switch strategy {
+			case MergeConflictKeep:
+				continue
+			case MergeConflictError:
+				return sdk.NewErrorFrom(sdk.ErrKeyAlreadyExist, "key %s exists in both applications", k.Name)
+			case MergeConflictOverwrite:
+				if err := DeleteKey(db, keepApp.ID, k.Name); err != nil {
+					return err
+				}
+			}
+		}
+
+		newKey := k
+		newKey.ID = 0
+		newKey.ApplicationID = keepApp.ID
+		if err := InsertKey(ctx, db, &newKey); err != nil {
+			return sdk.WrapError(err, "unable to insert key %s", k.Name)
+		}
+	}
+	return nil
+}
+
+func mergeDeploymentStrategies(ctx context.Context, db gorpmapper.SqlExecutorWithTx, keepID, mergeID int64, strategy MergeConflictStrategy) error {
+	deps, err := LoadAllDeploymnentForAppsWithDecryption(ctx, db, []int64{keepID, mergeID})
+	if err != nil {
+		return err
+	}
+	for projectIntegrationID, cfg := range deps[mergeID] {
+		if _, has := deps[keepID][projectIntegrationID]; has {
+			switch strategy {
+			case MergeConflictKeep:
+				continue
+			case MergeConflictError:
+				return sdk.NewErrorFrom(sdk.ErrAlreadyExist, "deployment strategy for integration %d exists in both applications", projectIntegrationID)
+			}
+		}
+		if err := setDeploymentStrategy(db, projectIntegrationID, keepID, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mergeRepositories(ctx context.Context, db gorpmapper.SqlExecutorWithTx, keepID, mergeID int64) error {
+	repos, err := LoadRepositories(db, mergeID)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if err := AddRepository(ctx, db, keepID, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mergeWebhooks(ctx context.Context, db gorpmapper.SqlExecutorWithTx, keepID, mergeID int64, strategy MergeConflictStrategy) error {
+	keepWhs, err := LoadWebhooks(db, keepID, true)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]sdk.ApplicationWebhook, len(keepWhs))
+	for _, wh := range keepWhs {
+		existing[wh.URL] = wh
+	}
+	mergeWhs, err := LoadWebhooks(db, mergeID, true)
+	if err != nil {
+		return err
+	}
+
+	for _, wh := range mergeWhs {
+		old, has := existing[wh.URL]
+		if !has {
+			newWh := sdk.ApplicationWebhook{ApplicationID: keepID, URL: wh.URL, Events: wh.Events, SigningKey: wh.SigningKey}
+			if err := InsertWebhook(ctx, db, &newWh); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch strategy {
+		case MergeConflictError:
+			return sdk.NewErrorFrom(sdk.ErrAlreadyExist, "webhook %s exists in both applications", wh.URL)
+		case MergeConflictOverwrite:
+			updated := old
+			updated.Events = wh.Events
+			updated.SigningKey = wh.SigningKey
+			if err := UpdateWebhook(ctx, db, &updated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
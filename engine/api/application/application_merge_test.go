@@ -0,0 +1,193 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/keys"
+	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+func insertMergeFixtures(t *testing.T, db gorpmapper.SqlExecutorWithTx, proj *sdk.Project, u sdk.Identifiable) (*sdk.Application, *sdk.Application) {
+	keep := &sdk.Application{Name: "keep-" + sdk.RandomString(5)}
+	merge := &sdk.Application{Name: "merge-" + sdk.RandomString(5)}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, keep))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, merge))
+
+	require.NoError(t, application.InsertVariable(context.TODO(), db, keep.ID, &sdk.ApplicationVariable{Name: "common", Type: sdk.TextVariable, Value: "keep_value"}, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, merge.ID, &sdk.ApplicationVariable{Name: "common", Type: sdk.TextVariable, Value: "merge_value"}, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, merge.ID, &sdk.ApplicationVariable{Name: "secret", Type: sdk.SecretVariable, Value: "secret_value"}, u))
+
+	for _, k := range []struct {
+		appID int64
+		name  string
+	}{{keep.ID, "common"}, {merge.ID, "common"}, {merge.ID, "other"}} {
+		ssh, err := keys.GenerateSSHKey(k.name)
+		require.NoError(t, err)
+		require.NoError(t, application.InsertKey(context.TODO(), db, &sdk.ApplicationKey{ApplicationID: k.appID, Type: sdk.KeyTypeSSH, Name: k.name, Public: ssh.Public, Private: ssh.Private}))
+	}
+	return keep, merge
+}
+
+func TestMerge(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	keep, merge := insertMergeFixtures(t, db, proj, u)
+
+	pip := sdk.Pipeline{ProjectID: proj.ID, ProjectKey: proj.Key, Name: "pip1"}
+	require.NoError(t, pipeline.InsertPipeline(db, &pip))
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		WorkflowData: sdk.WorkflowData{
+			Node: sdk.Node{
+				Type: sdk.NodeTypePipeline,
+				Context: &sdk.NodeContext{
+					PipelineID:    pip.ID,
+					ApplicationID: merge.ID,
+				},
+			},
+		},
+	}
+	require.NoError(t, workflow.RenameNode(context.TODO(), db, &w))
+	proj, _ = project.LoadByID(db, proj.ID, project.LoadOptions.WithApplications, project.LoadOptions.WithPipelines, project.LoadOptions.WithEnvironments, project.LoadOptions.WithGroups)
+	require.NoError(t, workflow.Insert(context.TODO(), db, cache, *proj, &w))
+
+	require.NoError(t, application.Merge(context.TODO(), db, keep.ID, merge.ID, application.MergeConflictKeep, u))
+
+	_, err := application.LoadByID(db, merge.ID)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+
+	apps, err := application.LoadByWorkflowID(db, w.ID)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, keep.ID, apps[0].ID)
+
+	vars, err := application.LoadAllVariablesWithDecrytion(db, keep.ID)
+	require.NoError(t, err)
+	require.Len(t, vars, 2)
+	require.Equal(t, "common", vars[0].Name)
+	require.Equal(t, "keep_value", vars[0].Value)
+	require.Equal(t, "secret", vars[1].Name)
+	require.Equal(t, "secret_value", vars[1].Value)
+
+	ks, err := application.LoadAllKeys(db, keep.ID)
+	require.NoError(t, err)
+	require.Len(t, ks, 2)
+}
+
+func TestMergeOverwrite(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	keep, merge := insertMergeFixtures(t, db, proj, u)
+	mergeKeys, err := application.LoadAllKeysWithPrivateContent(db, merge.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, application.Merge(context.TODO(), db, keep.ID, merge.ID, application.MergeConflictOverwrite, u))
+
+	v, err := application.LoadVariable(db, keep.ID, "common")
+	require.NoError(t, err)
+	require.Equal(t, "merge_value", v.Value)
+
+	ks, err := application.LoadAllKeysWithPrivateContent(db, keep.ID)
+	require.NoError(t, err)
+	require.Len(t, ks, 2)
+	for _, k := range ks {
+		if k.Name == "common" {
+			for _, mk := range mergeKeys {
+				if mk.Name == "common" {
+					require.Equal(t, mk.Private, k.Private)
+				}
+			}
+		}
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	keep, merge := insertMergeFixtures(t, db, proj, u)
+
+	err := application.Merge(context.TODO(), db, keep.ID, merge.ID, application.MergeConflictError, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrVariableExists))
+
+	err = application.Merge(context.TODO(), db, keep.ID, keep.ID, application.MergeConflictKeep, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	err = application.Merge(context.TODO(), db, keep.ID, merge.ID, "unknown", u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	key2 := sdk.RandomString(10)
+	proj2 := assets.InsertTestProject(t, db, cache, key2, key2)
+	other := &sdk.Application{Name: "other"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj2, other))
+	err = application.Merge(context.TODO(), db, keep.ID, other.ID, application.MergeConflictKeep, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+}
+
+func TestMergeChildTables(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	keep := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "keep"})
+	merge := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "merge"})
+	require.NoError(t, application.InsertVariable(context.TODO(), db, keep.ID, &sdk.ApplicationVariable{Name: "foo", Type: sdk.TextVariable, Value: "keep_value"}, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, merge.ID, &sdk.ApplicationVariable{Name: "FOO", Type: sdk.TextVariable, Value: "merge_value"}, u))
+	require.NoError(t, application.AddRepository(context.TODO(), db, merge.ID, "my/repo"))
+	require.NoError(t, application.InsertWebhook(context.TODO(), db, &sdk.ApplicationWebhook{ApplicationID: merge.ID, URL: "https://my-hook/notify", Events: []string{sdk.ApplicationChangeUpdate}, SigningKey: "my-signing-key"}))
+
+	// Read only applications can't be merged
+	require.NoError(t, application.SetReadOnly(db, keep.ID, true))
+	err := application.Merge(context.TODO(), db, keep.ID, merge.ID, application.MergeConflictKeep, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrForbidden))
+	require.NoError(t, application.SetReadOnly(db, keep.ID, false))
+
+	before, err := application.LoadByID(db, keep.ID)
+	require.NoError(t, err)
+
+	// Variables that only differ by case are a conflict handled by the strategy
+	require.NoError(t, application.Merge(context.TODO(), db, keep.ID, merge.ID, application.MergeConflictOverwrite, u))
+
+	v, err := application.LoadVariable(db, keep.ID, "foo")
+	require.NoError(t, err)
+	require.Equal(t, "merge_value", v.Value)
+
+	repos, err := application.LoadRepositories(db, keep.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"my/repo"}, repos)
+
+	whs, err := application.LoadWebhooks(db, keep.ID, true)
+	require.NoError(t, err)
+	require.Len(t, whs, 1)
+	require.Equal(t, "my-signing-key", whs[0].SigningKey)
+
+	after, err := application.LoadByID(db, keep.ID)
+	require.NoError(t, err)
+	require.True(t, after.LastModified.After(before.LastModified))
+}
@@ -14,22 +14,104 @@ import (
 	"github.com/ovh/cds/sdk/log"
 )
 
+// ImportMode defines what to do when an imported application already exists.
+type ImportMode string
+
+// Available import modes.
+const (
+	// ImportModeSkip keeps the existing application untouched.
+	ImportModeSkip ImportMode = "skip"
+	// ImportModeOverwrite updates the existing application, secrets omitted from the payload are preserved.
+	ImportModeOverwrite ImportMode = "overwrite"
+	// ImportModeError raises sdk.ErrApplicationExist.
+	ImportModeError ImportMode = "error"
+)
+
+// IsValid returns an error if the mode is unknown.
+func (m ImportMode) IsValid() error {
+	switch m {
+	case ImportModeSkip, ImportModeOverwrite, ImportModeError:
+		return nil
+	}
+	return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid import mode %q", m)
+}
+
 // ImportOptions are options to import application
 type ImportOptions struct {
 	Force          bool
 	FromRepository string
+	// Mode overrides Force if set. Without mode, Force means ImportModeOverwrite else ImportModeError.
+	Mode ImportMode
+}
+
+func (o ImportOptions) mode() ImportMode {
+	if o.Mode != "" {
+		return o.Mode
+	}
+	if o.Force {
+		return ImportModeOverwrite
+	}
+	return ImportModeError
+}
+
+// ImportOutcome is the result of the import of one application.
+type ImportOutcome string
+
+// Available import outcomes.
+const (
+	ImportOutcomeCreated ImportOutcome = "created"
+	ImportOutcomeUpdated ImportOutcome = "updated"
+	ImportOutcomeSkipped ImportOutcome = "skipped"
+	ImportOutcomeError   ImportOutcome = "error"
+)
+
+// ImportResult is the outcome of the import of one application.
+type ImportResult struct {
+	Name    string        `json:"name"`
+	Outcome ImportOutcome `json:"outcome"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// ImportSummary lists the outcome of each imported application.
+type ImportSummary []ImportResult
+
+// ParseAndImportAll parses and imports all given applications with given options.
+// Given db should be a transaction, so the import stops at the first error that is returned with the summary
+// and the caller should rollback.
+func ParseAndImportAll(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache cache.Store, proj sdk.Project, eapps []exportentities.Application, opts ImportOptions, decryptFunc keys.DecryptFunc, u sdk.Identifiable) (ImportSummary, []sdk.Message, error) {
+	summary := make(ImportSummary, 0, len(eapps))
+	var msgList []sdk.Message
+	for i := range eapps {
+		_, _, msgs, outcome, err := parseAndImport(ctx, db, cache, proj, &eapps[i], opts, decryptFunc, u)
+		msgList = append(msgList, msgs...)
+		if err != nil {
+			summary = append(summary, ImportResult{Name: eapps[i].Name, Outcome: ImportOutcomeError, Error: sdk.Cause(err).Error()})
+			return summary, msgList, err
+		}
+		summary = append(summary, ImportResult{Name: eapps[i].Name, Outcome: outcome})
+	}
+	return summary, msgList, nil
 }
 
 // ParseAndImport parse an exportentities.Application and insert or update the application in database
 func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache cache.Store, proj sdk.Project, eapp *exportentities.Application, opts ImportOptions, decryptFunc keys.DecryptFunc, u sdk.Identifiable) (*sdk.Application, []sdk.Variable, []sdk.Message, error) {
-	log.Info(ctx, "ParseAndImport>> Import application %s in project %s (force=%v)", eapp.Name, proj.Key, opts.Force)
+	app, secrets, msgList, _, err := parseAndImport(ctx, db, cache, proj, eapp, opts, decryptFunc, u)
+	return app, secrets, msgList, err
+}
+
+func parseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache cache.Store, proj sdk.Project, eapp *exportentities.Application, opts ImportOptions, decryptFunc keys.DecryptFunc, u sdk.Identifiable) (*sdk.Application, []sdk.Variable, []sdk.Message, ImportOutcome, error) {
+	mode := opts.mode()
+	log.Info(ctx, "ParseAndImport>> Import application %s in project %s (mode=%s)", eapp.Name, proj.Key, mode)
 	msgList := []sdk.Message{}
+	if err := mode.IsValid(); err != nil {
+		return nil, nil, msgList, ImportOutcomeError, err
+	}
 
 	//Check valid application name
 	rx := sdk.NamePatternRegex
 	if !rx.MatchString(eapp.Name) {
 		msgList = append(msgList, sdk.NewMessage(sdk.MsgWorkflowErrorBadApplicationName, eapp.Name))
-		return nil, nil, msgList, sdk.WrapError(sdk.ErrInvalidApplicationPattern, "application name %s do not respect pattern %s", eapp.Name, sdk.NamePattern)
+		return nil, nil, msgList, ImportOutcomeError, sdk.WrapError(sdk.ErrInvalidApplicationPattern, "application name %s do not respect pattern %s", eapp.Name, sdk.NamePattern)
 	}
 
 	//Check if app exist
@@ -39,16 +121,21 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 		LoadOptions.WithClearDeploymentStrategies,
 	)
 	if err != nil && !sdk.ErrorIs(err, sdk.ErrNotFound) {
-		return nil, nil, msgList, sdk.WrapError(err, "unable to load application")
+		return nil, nil, msgList, ImportOutcomeError, sdk.WrapError(err, "unable to load application")
 	}
 
-	//If the application exist and we don't want to force, raise an error
-	if oldApp != nil && !opts.Force {
-		return nil, nil, msgList, sdk.WithStack(sdk.ErrApplicationExist)
+	//If the application exist, apply the import mode
+	if oldApp != nil {
+		switch mode {
+		case ImportModeError:
+			return nil, nil, msgList, ImportOutcomeError, sdk.WithStack(sdk.ErrApplicationExist)
+		case ImportModeSkip:
+			return oldApp, nil, msgList, ImportOutcomeSkipped, nil
+		}
 	}
 
 	if oldApp != nil && oldApp.FromRepository != "" && opts.FromRepository != oldApp.FromRepository {
-		return nil, nil, msgList, sdk.NewErrorFrom(sdk.ErrApplicationAsCodeOverride, "unable to update existing ascode application from %s", oldApp.FromRepository)
+		return nil, nil, msgList, ImportOutcomeError, sdk.NewErrorFrom(sdk.ErrApplicationAsCodeOverride, "unable to update existing ascode application from %s", oldApp.FromRepository)
 	}
 
 	//Craft the application
@@ -66,9 +153,14 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 		case "":
 			v.Type = sdk.StringVariable
 		case sdk.SecretVariable:
+			// Preserve the existing secret when the payload omits it
+			if oldVar := getOmittedSecretVariable(oldApp, p, v.Value); oldVar != nil {
+				v.Value = oldVar.Value
+				break
+			}
 			secret, err := decryptFunc(db, proj.ID, v.Value)
 			if err != nil {
-				return app, nil, msgList, sdk.WrapError(sdk.NewError(sdk.ErrWrongRequest, err), "unable to decrypt secret variable")
+				return app, nil, msgList, ImportOutcomeError, sdk.WrapError(sdk.NewError(sdk.ErrWrongRequest, err), "unable to decrypt secret variable")
 			}
 			v.Value = secret
 		}
@@ -88,7 +180,7 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 	for kname, kval := range eapp.Keys {
 		if !strings.HasPrefix(kname, "app-") {
 			msgList = append(msgList, sdk.NewMessage(sdk.MsgWorkflowErrorUnknownKey, kname))
-			return app, nil, msgList, sdk.WrapError(sdk.ErrInvalidKeyName, "unable to parse key %s", kname)
+			return app, nil, msgList, ImportOutcomeError, sdk.WrapError(sdk.ErrInvalidKeyName, "unable to parse key %s", kname)
 		}
 
 		var oldKey *sdk.ApplicationKey
@@ -113,7 +205,7 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 
 		kk, err := keys.Parse(db, proj.ID, kname, kval, decryptFunc)
 		if err != nil {
-			return app, nil, msgList, sdk.ErrorWithFallback(err, sdk.ErrWrongRequest, "unable to parse key %s", kname)
+			return app, nil, msgList, ImportOutcomeError, sdk.ErrorWithFallback(err, sdk.ErrWrongRequest, "unable to parse key %s", kname)
 		}
 
 		k := sdk.ApplicationKey{
@@ -154,12 +246,12 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 		app.RepositoryStrategy.ConnectionType = "https"
 	}
 	if app.RepositoryStrategy.ConnectionType == "ssh" && app.RepositoryStrategy.SSHKey == "" {
-		return app, nil, msgList, sdk.NewErrorFrom(sdk.ErrInvalidApplicationRepoStrategy, "could not import application %s with a connection type ssh without ssh key", app.Name)
+		return app, nil, msgList, ImportOutcomeError, sdk.NewErrorFrom(sdk.ErrInvalidApplicationRepoStrategy, "could not import application %s with a connection type ssh without ssh key", app.Name)
 	}
 	if eapp.VCSPassword != "" {
 		clearPWD, err := decryptFunc(db, proj.ID, eapp.VCSPassword)
 		if err != nil {
-			return app, nil, msgList, sdk.WrapError(sdk.NewError(sdk.ErrWrongRequest, err), "unable to decrypt vcs password")
+			return app, nil, msgList, ImportOutcomeError, sdk.WrapError(sdk.NewError(sdk.ErrWrongRequest, err), "unable to decrypt vcs password")
 		}
 		app.RepositoryStrategy.Password = clearPWD
 		applicationSecrets = append(applicationSecrets, sdk.Variable{
@@ -167,6 +259,9 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 			Type:  sdk.SecretVariable,
 			Value: clearPWD,
 		})
+	} else if oldApp != nil && oldApp.RepositoryStrategy.ConnectionType == app.RepositoryStrategy.ConnectionType {
+		// Preserve the existing password, it will be reloaded on update
+		app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
 	}
 
 	// deployment strategies
@@ -176,7 +271,7 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 		projIt, has := proj.GetIntegration(pfName)
 		if !has {
 			msgList = append(msgList, sdk.NewMessage(sdk.MsgWorkflowErrorBadIntegrationName, pfName))
-			return app, nil, msgList, sdk.WrapError(sdk.NewErrorFrom(sdk.ErrWrongRequest, "deployment platform not found"), "deployment platform %s not found", pfName)
+			return app, nil, msgList, ImportOutcomeError, sdk.WrapError(sdk.NewErrorFrom(sdk.ErrWrongRequest, "deployment platform not found"), "deployment platform %s not found", pfName)
 		}
 		if projIt.Model.DeploymentDefaultConfig != nil {
 			deploymentStrategies[pfName] = projIt.Model.DeploymentDefaultConfig.Clone()
@@ -193,11 +288,17 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 
 		// update deployment strategy with given values from request
 		for k, v := range pfConfig {
+			if v.Type == sdk.SecretVariable && (v.Value == "" || v.Value == sdk.PasswordPlaceholder) {
+				// Preserve the existing secret merged from old application when the payload omits it
+				if _, has := deploymentStrategies[pfName][k]; has && oldApp != nil {
+					continue
+				}
+			}
 			if v.Value != "" {
 				if v.Type == sdk.SecretVariable {
 					clearPWD, err := decryptFunc(db, proj.ID, v.Value)
 					if err != nil {
-						return app, nil, nil, sdk.WrapError(sdk.NewError(sdk.ErrWrongRequest, err), "unable to decrypt deployment strategy password")
+						return app, nil, nil, ImportOutcomeError, sdk.WrapError(sdk.NewError(sdk.ErrWrongRequest, err), "unable to decrypt deployment strategy password")
 					}
 					v.Value = clearPWD
 					applicationSecrets = append(applicationSecrets, sdk.Variable{
@@ -229,5 +330,24 @@ func ParseAndImport(ctx context.Context, db gorpmapper.SqlExecutorWithTx, cache
 	close(msgChan)
 	done.Wait()
 
-	return app, applicationSecrets, msgList, globalError
+	if globalError != nil {
+		return app, applicationSecrets, msgList, ImportOutcomeError, globalError
+	}
+	if oldApp != nil {
+		return app, applicationSecrets, msgList, ImportOutcomeUpdated, nil
+	}
+	return app, applicationSecrets, msgList, ImportOutcomeCreated, nil
+}
+
+// getOmittedSecretVariable returns the existing secret variable of the application if given value is omitted.
+func getOmittedSecretVariable(oldApp *sdk.Application, name, value string) *sdk.ApplicationVariable {
+	if oldApp == nil || (value != "" && value != sdk.PasswordPlaceholder) {
+		return nil
+	}
+	for i := range oldApp.Variables {
+		if oldApp.Variables[i].Name == name && oldApp.Variables[i].Type == sdk.SecretVariable {
+			return &oldApp.Variables[i]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,67 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+func clearDecrypt(_ gorp.SqlExecutor, _ int64, s string) (string, error) { return s, nil }
+
+func TestParseAndImportAllModes(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	eapps := []exportentities.Application{{
+		Name:        "app1",
+		VCSUser:     "user",
+		VCSPassword: "vcs_secret",
+		Variables: map[string]exportentities.VariableValue{
+			"secret": {Type: sdk.SecretVariable, Value: "secret_value"},
+		},
+	}}
+	summary, _, err := application.ParseAndImportAll(context.TODO(), db, cache, *proj, eapps, application.ImportOptions{Mode: application.ImportModeError}, clearDecrypt, u)
+	require.NoError(t, err)
+	require.Equal(t, application.ImportSummary{{Name: "app1", Outcome: application.ImportOutcomeCreated}}, summary)
+
+	// The payload omits secrets
+	eapps[0].VCSPassword = ""
+	eapps[0].Variables["secret"] = exportentities.VariableValue{Type: sdk.SecretVariable, Value: sdk.PasswordPlaceholder}
+	eapps = append(eapps, exportentities.Application{Name: "app2"})
+
+	summary, _, err = application.ParseAndImportAll(context.TODO(), db, cache, *proj, eapps, application.ImportOptions{Mode: application.ImportModeSkip}, clearDecrypt, u)
+	require.NoError(t, err)
+	require.Equal(t, application.ImportSummary{
+		{Name: "app1", Outcome: application.ImportOutcomeSkipped},
+		{Name: "app2", Outcome: application.ImportOutcomeCreated},
+	}, summary)
+
+	summary, _, err = application.ParseAndImportAll(context.TODO(), db, cache, *proj, eapps[:1], application.ImportOptions{Mode: application.ImportModeOverwrite}, clearDecrypt, u)
+	require.NoError(t, err)
+	require.Equal(t, application.ImportSummary{{Name: "app1", Outcome: application.ImportOutcomeUpdated}}, summary)
+
+	app, err := application.LoadByNameWithClearVCSStrategyPassword(context.TODO(), db, proj.Key, "app1", application.LoadOptions.WithVariablesWithClearPassword)
+	require.NoError(t, err)
+	require.Equal(t, "vcs_secret", app.RepositoryStrategy.Password)
+	require.Len(t, app.Variables, 1)
+	require.Equal(t, "secret_value", app.Variables[0].Value)
+
+	summary, _, err = application.ParseAndImportAll(context.TODO(), db, cache, *proj, eapps, application.ImportOptions{Mode: application.ImportModeError}, clearDecrypt, u)
+	require.Error(t, err)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrApplicationExist))
+	require.Len(t, summary, 1)
+	require.Equal(t, application.ImportOutcomeError, summary[0].Outcome)
+	require.NotEmpty(t, summary[0].Error)
+}
@@ -0,0 +1,121 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+const snapshotVersion = 1
+
+// applicationSnapshot is the serialized form of an application snapshot.
+// The whole application including its secrets is encrypted in Cipher.
+type applicationSnapshot struct {
+	Version         int       `json:"version"`
+	ApplicationName string    `json:"application_name"`
+	Created         time.Time `json:"created"`
+	Cipher          []byte    `json:"cipher"`
+}
+
+// snapshotContent is the application encrypted in a snapshot, it is serialized without masking secrets.
+type snapshotContent sdk.Application
+
+// Snapshot returns a serialized and encrypted copy of an application with its variables, keys and deployment strategies.
+func Snapshot(ctx context.Context, db gorpmapper.SqlExecutorWithTx, appID int64) ([]byte, error) {
+	app, err := loadByIDWithClearVCSStrategyPassword(ctx, db, appID,
+		LoadOptions.WithVariablesWithClearPassword,
+		LoadOptions.WithClearKeys,
+		LoadOptions.WithClearDeploymentStrategies,
+		LoadOptions.WithIcon,
+	)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot load application %d", appID)
+	}
+
+	s := applicationSnapshot{
+		Version:         snapshotVersion,
+		ApplicationName: app.Name,
+		Created:         time.Now(),
+	}
+	if err := gorpmapping.Mapper.Encrypt(snapshotContent(*app), &s.Cipher, []interface{}{s.Version, s.ApplicationName}); err != nil {
+		return nil, err
+	}
+
+	btes, err := json.Marshal(s)
+	return btes, sdk.WithStack(err)
+}
+
+// Restore creates a new application in given project from a snapshot created with Snapshot.
+// Given db should be a transaction so nothing is written if the restore fails.
+func Restore(ctx context.Context, db gorpmapper.SqlExecutorWithTx, projectID int64, snapshot []byte, u sdk.Identifiable) (*sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+
+	var s applicationSnapshot
+	if err := json.Unmarshal(snapshot, &s); err != nil {
+		return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid application snapshot"))
+	}
+	if s.Version != snapshotVersion {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "unsupported application snapshot version %d", s.Version)
+	}
+
+	var app sdk.Application
+	if err := gorpmapping.Mapper.Decrypt(s.Cipher, &app, []interface{}{s.Version, s.ApplicationName}); err != nil {
+		return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot decrypt application snapshot"))
+	}
+
+	projectKey, err := db.SelectStr("SELECT projectkey FROM project WHERE id = $1", projectID)
+	if err != nil {
+		return nil, sdk.WithStack(err)
+	}
+	if projectKey == "" {
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+
+	variables, keys, deploymentStrategies := app.Variables, app.Keys, app.DeploymentStrategies
+	app.ID = 0
+	app.Variables = nil
+	app.Keys = nil
+	app.DeploymentStrategies = nil
+	if err := Insert(ctx, db, sdk.Project{ID: projectID, Key: projectKey}, &app); err != nil {
+		return nil, err
+	}
+
+	for i := range variables {
+		v := sdk.ApplicationVariable{Name: variables[i].Name, Type: variables[i].Type, Value: variables[i].Value}
+		if err := InsertVariable(ctx, db, app.ID, &v, u); err != nil {
+			return nil, err
+		}
+		app.Variables = append(app.Variables, v)
+	}
+
+	for i := range keys {
+		k := keys[i]
+		k.ID = 0
+		k.ApplicationID = app.ID
+		if err := InsertKey(ctx, db, &k); err != nil {
+			return nil, sdk.WrapError(err, "unable to insert key %s", k.Name)
+		}
+		app.Keys = append(app.Keys, k)
+	}
+
+	for pfName, pfConfig := range deploymentStrategies {
+		modelID, err := db.SelectInt("SELECT integration_model_id FROM project_integration WHERE project_id = $1 AND name = $2", projectID, pfName)
+		if err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		if modelID == 0 {
+			return nil, sdk.NewErrorFrom(sdk.ErrNotFound, "integration %s not found", pfName)
+		}
+		if err := SetDeploymentStrategy(db, projectID, app.ID, modelID, pfName, pfConfig); err != nil {
+			return nil, sdk.WrapError(err, "unable to set deployment strategy %s", pfName)
+		}
+	}
+
+	return LoadByID(db, app.ID, LoadOptions.WithVariables, LoadOptions.WithKeys, LoadOptions.WithDeploymentStrategies)
+}
@@ -0,0 +1,60 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	app := &sdk.Application{
+		Name:        "my-app",
+		Description: "my description",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "user",
+			Password:       "vcs_secret",
+		},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: "clear", Type: sdk.TextVariable, Value: "clear_value"}, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: "secret", Type: sdk.SecretVariable, Value: "secret_value"}, u))
+
+	snapshot, err := application.Snapshot(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.NotContains(t, string(snapshot), "secret_value")
+	require.NotContains(t, string(snapshot), "vcs_secret")
+
+	key2 := sdk.RandomString(10)
+	proj2 := assets.InsertTestProject(t, db, cache, key2, key2)
+
+	restored, err := application.Restore(context.TODO(), db, proj2.ID, snapshot, u)
+	require.NoError(t, err)
+	require.NotEqual(t, app.ID, restored.ID)
+	require.Equal(t, proj2.ID, restored.ProjectID)
+	require.Equal(t, "my description", restored.Description)
+	require.Equal(t, sdk.PasswordPlaceholder, restored.RepositoryStrategy.Password)
+
+	clearApp, err := application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, restored.ID, application.LoadOptions.WithVariablesWithClearPassword)
+	require.NoError(t, err)
+	require.Equal(t, "vcs_secret", clearApp.RepositoryStrategy.Password)
+	require.Len(t, clearApp.Variables, 2)
+	require.Equal(t, "clear_value", clearApp.Variables[0].Value)
+	require.Equal(t, "secret_value", clearApp.Variables[1].Value)
+
+	_, err = application.Restore(context.TODO(), db, proj2.ID, []byte("{}"), u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+}
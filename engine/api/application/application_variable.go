@@ -1,12 +1,16 @@
 package application
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/go-gorp/gorp"
 
+	"github.com/ovh/cds/engine/gorpmapper"
 	"github.com/ovh/cds/sdk"
 )
 
@@ -90,3 +94,225 @@ func LoadVariableAudits(db gorp.SqlExecutor, appID, varID int64) ([]sdk.Applicat
 	}
 	return avas, nil
 }
+
+// ExportVariables returns all the variables of an application ordered by name, secret values are masked.
+func ExportVariables(ctx context.Context, db gorp.SqlExecutor, appID int64) ([]sdk.Variable, error) {
+	appVars, err := LoadAllVariables(db, appID)
+	if err != nil {
+		return nil, err
+	}
+	vars := make([]sdk.Variable, len(appVars))
+	for i, v := range appVars {
+		vars[i] = sdk.Variable{Name: v.Name, Type: v.Type, Value: v.Value}
+		if sdk.NeedPlaceholder(v.Type) {
+			vars[i].Value = sdk.PasswordPlaceholder
+		}
+	}
+	return vars, nil
+}
+
+// ExportVariablesDotenv returns the non secret variables of an application ordered by name as a dotenv file.
+// Each line is NAME="value", backslashes, double quotes, dollar signs and line breaks in values are escaped.
+func ExportVariablesDotenv(ctx context.Context, db gorp.SqlExecutor, appID int64) ([]byte, error) {
+	vars, err := ExportVariables(ctx, db, appID)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, v := range vars {
+		if sdk.NeedPlaceholder(v.Type) {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s=\"%s\"\n", v.Name, dotenvEscaper.Replace(v.Value))
+	}
+	return buf.Bytes(), nil
+}
+
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+
+// ImportVariables inserts or updates given variables in an application, existing variables are skipped, updated
+// or raise sdk.ErrVariableExists depending on given mode. A secret variable with the placeholder value keeps its
+// existing value. The application is updated so its last modification date changes.
+func ImportVariables(ctx context.Context, db gorpmapper.SqlExecutorWithTx, appID int64, vars []sdk.Variable, mode ImportMode, u sdk.Identifiable) error {
+	if err := mode.IsValid(); err != nil {
+		return err
+	}
+	if err := LockApplication(ctx, db, appID); err != nil {
+		return err
+	}
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
+	app, err := LoadByID(db, appID)
+	if err != nil {
+		return err
+	}
+
+	existingVars, err := LoadAllVariablesWithDecrytion(db, appID)
+	if err != nil {
+		return err
+	}
+	// Variable names are unique case-insensitively
+	existing := make(map[string]sdk.ApplicationVariable, len(existingVars))
+	for _, v := range existingVars {
+		existing[strings.ToLower(v.Name)] = v
+	}
+
+	for _, v := range vars {
+		newVar := sdk.ApplicationVariable{Name: v.Name, Type: v.Type, Value: v.Value}
+		old, has := existing[strings.ToLower(v.Name)]
+		isPlaceholder := sdk.NeedPlaceholder(v.Type) && v.Value == sdk.PasswordPlaceholder
+		if !has {
+			if isPlaceholder {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "variable %s: missing secret value", v.Name)
+			}
+			if err := InsertVariable(ctx, db, appID, &newVar, u); err != nil {
+				return sdk.WrapError(err, "cannot insert variable %s", v.Name)
+			}
+			existing[strings.ToLower(v.Name)] = newVar
+			continue
+		}
+
+		switch mode {
+		case ImportModeSkip:
+			continue
+		case ImportModeError:
+			return sdk.NewErrorFrom(sdk.ErrVariableExists, "variable %s already exists", v.Name)
+		}
+		if isPlaceholder {
+			if old.Type != v.Type {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "variable %s: missing secret value", v.Name)
+			}
+			newVar.Value = old.Value
+		}
+		newVar.ID = old.ID
+		newVar.Name = old.Name
+		if err := UpdateVariable(ctx, db, appID, &newVar, &old, u); err != nil {
+			return sdk.WrapError(err, "cannot update variable %s", v.Name)
+		}
+	}
+
+	return Update(ctx, db, app)
+}
+
+// CopyVariablesResult lists by name the variables copied to an application.
+type CopyVariablesResult struct {
+	ApplicationID int64    `json:"application_id"`
+	Inserted      []string `json:"inserted"`
+	Updated       []string `json:"updated"`
+	Skipped       []string `json:"skipped"`
+}
+
+// CopyVariables imports the variables of an application into each destination application with ImportVariables and
+// given mode. Secret variables are only copied if withSecrets is true. Given db should be a transaction so nothing is
+// written if the copy to one of the applications fails. Results are ordered like given destination ids.
+func CopyVariables(ctx context.Context, db gorpmapper.SqlExecutorWithTx, srcAppID int64, destAppIDs []int64, mode ImportMode, withSecrets bool, u sdk.Identifiable) ([]CopyVariablesResult, error) {
+	if err := mode.IsValid(); err != nil {
+		return nil, err
+	}
+	for _, id := range destAppIDs {
+		if id == srcAppID {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot copy variables of application %d to itself", srcAppID)
+		}
+	}
+
+	var srcVars []sdk.ApplicationVariable
+	var err error
+	if withSecrets {
+		srcVars, err = LoadAllVariablesWithDecrytion(db, srcAppID)
+	} else {
+		srcVars, err = LoadAllVariables(db, srcAppID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	vars := make([]sdk.Variable, 0, len(srcVars))
+	for _, v := range srcVars {
+		if !withSecrets && sdk.NeedPlaceholder(v.Type) {
+			continue
+		}
+		vars = append(vars, sdk.Variable{Name: v.Name, Type: v.Type, Value: v.Value})
+	}
+
+	if err := LockApplications(ctx, db, destAppIDs); err != nil {
+		return nil, err
+	}
+
+	res := make([]CopyVariablesResult, 0, len(destAppIDs))
+	for _, id := range destAppIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		existingVars, err := LoadAllVariables(db, id)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]struct{}, len(existingVars))
+		for _, v := range existingVars {
+			existing[strings.ToLower(v.Name)] = struct{}{}
+		}
+		r := CopyVariablesResult{ApplicationID: id, Inserted: []string{}, Updated: []string{}, Skipped: []string{}}
+		for _, v := range vars {
+			switch _, has := existing[strings.ToLower(v.Name)]; {
+			case !has:
+				r.Inserted = append(r.Inserted, v.Name)
+				existing[strings.ToLower(v.Name)] = struct{}{}
+			case mode == ImportModeSkip:
+				r.Skipped = append(r.Skipped, v.Name)
+			default:
+				r.Updated = append(r.Updated, v.Name)
+			}
+		}
+		if err := ImportVariables(ctx, db, id, vars, mode, u); err != nil {
+			return nil, sdk.WrapError(err, "cannot copy variables to application %d", id)
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// RenameVariable renames a variable of an application and updates the application so its signature and last
+// modification date are refreshed. Given db should be a transaction so nothing is written if the rename fails.
+// A variable with the new name must not exist. References to the variable in pipelines or workflows are not updated.
+// The rename is audited as an update of the variable by given user.
+func RenameVariable(ctx context.Context, db gorpmapper.SqlExecutorWithTx, appID int64, oldName, newName string, u sdk.Identifiable) error {
+	if !sdk.NamePatternRegex.MatchString(newName) {
+		return sdk.NewErrorFrom(sdk.ErrInvalidName, "variable name should match pattern %s", sdk.NamePattern)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if err := LockApplication(ctx, db, appID); err != nil {
+		return err
+	}
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
+	app, err := LoadByID(db, appID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := LoadVariable(db, appID, newName); err == nil {
+		return sdk.NewErrorFrom(sdk.ErrVariableExists, "variable %s already exists", newName)
+	} else if !sdk.ErrorIs(err, sdk.ErrNotFound) {
+		return err
+	}
+
+	v, err := LoadVariable(db, appID, oldName)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load variable %s", oldName)
+	}
+	// The secret value is encrypted with the variable name so it has to be decrypted to be encrypted again
+	v, err = LoadVariableWithDecryption(db, appID, v.ID, oldName)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load variable %s", oldName)
+	}
+	before := *v
+	v.Name = newName
+	if err := UpdateVariable(ctx, db, appID, v, &before, u); err != nil {
+		return sdk.WrapError(err, "cannot rename variable %s", oldName)
+	}
+
+	return Update(ctx, db, app)
+}
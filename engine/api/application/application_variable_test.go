@@ -0,0 +1,186 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestExportImportVariables(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+	u, _ := assets.InsertLambdaUser(t, db)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	src := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-src",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+			{Name: "my-text", Type: sdk.StringVariable, Value: "my-text-value"},
+		},
+	})
+	dst := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-dst",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-dst-secret-value"},
+		},
+	})
+
+	vars, err := application.ExportVariables(context.TODO(), db, src.ID)
+	require.NoError(t, err)
+	require.Equal(t, []sdk.Variable{
+		{Name: "my-secret", Type: sdk.SecretVariable, Value: sdk.PasswordPlaceholder},
+		{Name: "my-text", Type: sdk.StringVariable, Value: "my-text-value"},
+	}, vars)
+
+	require.Error(t, application.ImportVariables(context.TODO(), db, dst.ID, vars, application.ImportModeError, u))
+
+	// Masked secret keeps the value of the destination application
+	require.NoError(t, application.ImportVariables(context.TODO(), db, dst.ID, vars, application.ImportModeOverwrite, u))
+	res, err := application.LoadAllVariablesWithDecrytion(db, dst.ID)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, "my-dst-secret-value", res[0].Value)
+	require.Equal(t, "my-text-value", res[1].Value)
+
+	// A new secret variable needs a value
+	other := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-other"})
+	require.Error(t, application.ImportVariables(context.TODO(), db, other.ID, vars, application.ImportModeSkip, u))
+}
+
+func TestExportVariablesDotenv(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-app",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+			{Name: "my-text", Type: sdk.StringVariable, Value: "a \"quoted\" $HOME\nline \\ end"},
+			{Name: "my-bool", Type: sdk.BooleanVariable, Value: "true"},
+		},
+	})
+
+	res, err := application.ExportVariablesDotenv(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-bool=\"true\"\nmy-text=\"a \\\"quoted\\\" \\$HOME\\nline \\\\ end\"\n", string(res))
+}
+
+func TestRenameVariable(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+	u, _ := assets.InsertLambdaUser(t, db)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-app",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+			{Name: "my-text", Type: sdk.StringVariable, Value: "my-text-value"},
+		},
+	})
+
+	err := application.RenameVariable(context.TODO(), db, app.ID, "my-secret", "my-text", u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrVariableExists))
+	err = application.RenameVariable(context.TODO(), db, app.ID, "my-unknown", "my-other", u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+
+	require.NoError(t, application.RenameVariable(context.TODO(), db, app.ID, "my-secret", "my-renamed-secret", u))
+
+	// Variable and application signatures are checked by the loaders
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.True(t, res.LastModified.After(app.LastModified))
+	vars, err := application.LoadAllVariablesWithDecrytion(db, app.ID)
+	require.NoError(t, err)
+	require.Len(t, vars, 2)
+	require.Equal(t, "my-renamed-secret", vars[0].Name)
+	require.Equal(t, "my-secret-value", vars[0].Value)
+	require.Equal(t, "my-text", vars[1].Name)
+	audits, err := application.LoadVariableAudits(db, app.ID, vars[0].ID)
+	require.NoError(t, err)
+	require.Len(t, audits, 1)
+	require.NotNil(t, audits[0].VariableBefore)
+	require.Equal(t, "my-secret", audits[0].VariableBefore.Name)
+	require.Equal(t, "my-renamed-secret", audits[0].VariableAfter.Name)
+}
+
+func TestCopyVariables(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+	u, _ := assets.InsertLambdaUser(t, db)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	src := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-src",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+			{Name: "my-text", Type: sdk.StringVariable, Value: "my-text-value"},
+		},
+	})
+	dst1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-dst1"})
+	dst2 := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-dst2",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-text", Type: sdk.StringVariable, Value: "my-dst-value"},
+		},
+	})
+
+	// Secrets are not copied without opt-in
+	res, err := application.CopyVariables(context.TODO(), db, src.ID, []int64{dst1.ID, dst2.ID}, application.ImportModeSkip, false, u)
+	require.NoError(t, err)
+	require.Equal(t, []application.CopyVariablesResult{
+		{ApplicationID: dst1.ID, Inserted: []string{"my-text"}, Updated: []string{}, Skipped: []string{}},
+		{ApplicationID: dst2.ID, Inserted: []string{}, Updated: []string{}, Skipped: []string{"my-text"}},
+	}, res)
+	vars, err := application.LoadAllVariablesWithDecrytion(db, dst1.ID)
+	require.NoError(t, err)
+	require.Len(t, vars, 1)
+	require.Equal(t, "my-text-value", vars[0].Value)
+	vars, err = application.LoadAllVariablesWithDecrytion(db, dst2.ID)
+	require.NoError(t, err)
+	require.Len(t, vars, 1)
+	require.Equal(t, "my-dst-value", vars[0].Value)
+
+	res, err = application.CopyVariables(context.TODO(), db, src.ID, []int64{dst2.ID}, application.ImportModeOverwrite, true, u)
+	require.NoError(t, err)
+	require.Equal(t, []application.CopyVariablesResult{
+		{ApplicationID: dst2.ID, Inserted: []string{"my-secret"}, Updated: []string{"my-text"}, Skipped: []string{}},
+	}, res)
+	vars, err = application.LoadAllVariablesWithDecrytion(db, dst2.ID)
+	require.NoError(t, err)
+	require.Len(t, vars, 2)
+	require.Equal(t, "my-secret-value", vars[0].Value)
+	require.Equal(t, "my-text-value", vars[1].Value)
+
+	_, err = application.CopyVariables(context.TODO(), db, src.ID, []int64{dst1.ID}, application.ImportModeError, false, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrVariableExists))
+	_, err = application.CopyVariables(context.TODO(), db, src.ID, []int64{src.ID}, application.ImportModeSkip, false, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	// Names are matched case-insensitively, the existing name is kept
+	dst3 := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-dst3",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "MY-TEXT", Type: sdk.StringVariable, Value: "my-dst-value"},
+		},
+	})
+	res, err = application.CopyVariables(context.TODO(), db, src.ID, []int64{dst3.ID}, application.ImportModeOverwrite, false, u)
+	require.NoError(t, err)
+	require.Equal(t, []application.CopyVariablesResult{
+		{ApplicationID: dst3.ID, Inserted: []string{}, Updated: []string{"my-text"}, Skipped: []string{}},
+	}, res)
+	vars, err = application.LoadAllVariablesWithDecrytion(db, dst3.ID)
+	require.NoError(t, err)
+	require.Len(t, vars, 1)
+	require.Equal(t, "MY-TEXT", vars[0].Name)
+	require.Equal(t, "my-text-value", vars[0].Value)
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// ApplicationClosure is an application with all the entities it depends on.
+type ApplicationClosure struct {
+	Application  sdk.Application   `json:"application"`
+	Environments []sdk.Environment `json:"environments"`
+	Pipelines    []sdk.Pipeline    `json:"pipelines"`
+}
+
+// LoadFull returns an application with its variables, keys, deployment strategies and icon, and the environments
+// and pipelines it is used with in workflow nodes. Secrets are masked. Environments and pipelines are loaded without
+// their own dependencies.
+func LoadFull(ctx context.Context, db gorp.SqlExecutor, appID int64) (*ApplicationClosure, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.id = $1`).Args(appID)
+	app, err := get(ctx, db, "", []LoadOptionFunc{
+		LoadOptions.WithVariables,
+		LoadOptions.WithKeys,
+		LoadOptions.WithDeploymentStrategies,
+		LoadOptions.WithIcon,
+	}, query)
+	if err != nil {
+		return nil, err
+	}
+
+	envs, err := LoadEnvironmentsByApplicationIDs(db, []int64{appID})
+	if err != nil {
+		return nil, err
+	}
+	pips, err := LoadPipelinesByApplicationIDs(db, []int64{appID})
+	if err != nil {
+		return nil, err
+	}
+
+	res := ApplicationClosure{
+		Application:  *app,
+		Environments: envs[appID],
+		Pipelines:    pips[appID],
+	}
+	if res.Environments == nil {
+		res.Environments = []sdk.Environment{}
+	}
+	if res.Pipelines == nil {
+		res.Pipelines = []sdk.Pipeline{}
+	}
+	return &res, nil
+}
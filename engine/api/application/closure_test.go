@@ -0,0 +1,66 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadFull(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-app",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+		},
+	})
+
+	res, err := application.LoadFull(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, app.Name, res.Application.Name)
+	require.Len(t, res.Application.Variables, 1)
+	require.NotEqual(t, "my-secret-value", res.Application.Variables[0].Value)
+	require.Empty(t, res.Environments)
+	require.Empty(t, res.Pipelines)
+
+	pip := sdk.Pipeline{ProjectID: proj.ID, ProjectKey: proj.Key, Name: "pip1"}
+	require.NoError(t, pipeline.InsertPipeline(db, &pip))
+	proj, _ = project.LoadByID(db, proj.ID, project.LoadOptions.WithApplications, project.LoadOptions.WithPipelines, project.LoadOptions.WithEnvironments, project.LoadOptions.WithGroups)
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		WorkflowData: sdk.WorkflowData{
+			Node: sdk.Node{
+				Type: sdk.NodeTypePipeline,
+				Context: &sdk.NodeContext{
+					PipelineID:    pip.ID,
+					ApplicationID: app.ID,
+				},
+			},
+		},
+	}
+	require.NoError(t, workflow.RenameNode(context.TODO(), db, &w))
+	require.NoError(t, workflow.Insert(context.TODO(), db, cache, *proj, &w))
+
+	res, err = application.LoadFull(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Len(t, res.Pipelines, 1)
+	require.Equal(t, pip.Name, res.Pipelines[0].Name)
+
+	_, err = application.LoadFull(context.TODO(), db, app.ID+1000)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
@@ -0,0 +1,26 @@
+package application
+
+import (
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// SetColor sets the color used to group an application in the UI, a hex code or a color of
+// sdk.ApplicationColorPalette. An empty color removes it. The color is not part of the signed data.
+func SetColor(db gorp.SqlExecutor, appID int64, color string) error {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color != "" && !sdk.IsValidApplicationColor(color) {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "application color %q should be a hex code such as #3c8dbc or one of %s", color, strings.Join(sdk.ApplicationColorPalette, ", "))
+	}
+	return setColumn(db, appID, columnColor, color)
+}
+
+// LoadColor returns the color of an application, an empty string if it has none.
+func LoadColor(db gorp.SqlExecutor, appID int64) (string, error) {
+	var color string
+	err := loadColumn(db, appID, columnColor, &color)
+	return color, err
+}
@@ -0,0 +1,46 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestColor(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	color, err := application.LoadColor(db, app.ID)
+	require.NoError(t, err)
+	require.Empty(t, color)
+
+	require.NoError(t, application.SetColor(db, app.ID, " #3C8DBC "))
+	color, err = application.LoadColor(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "#3c8dbc", color)
+
+	for _, c := range []string{"3c8dbc", "#12345", "not-a-color"} {
+		require.True(t, sdk.ErrorIs(application.SetColor(db, app.ID, c), sdk.ErrWrongRequest), c)
+	}
+
+	require.NoError(t, application.SetColor(db, app.ID, "teal"))
+	names, err := application.LoadAllNames(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, names, 1)
+	require.Equal(t, "teal", names[0].Color)
+
+	require.NoError(t, application.SetColor(db, app.ID, ""))
+	color, err = application.LoadColor(db, app.ID)
+	require.NoError(t, err)
+	require.Empty(t, color)
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+// SetMaxConcurrentRuns sets the maximum number of node runs of an application that can be waiting or building at the
+// same time, zero means unlimited. It is checked by ConcurrentRunsReached and is not part of the signed data.
+func SetMaxConcurrentRuns(db gorp.SqlExecutor, appID, max int64) error {
+	if max < 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid max concurrent runs %d", max)
+	}
+	return setColumn(db, appID, columnMaxConcurrentRuns, max)
+}
+
+// LoadMaxConcurrentRuns returns the maximum number of node runs of an application run in parallel.
+func LoadMaxConcurrentRuns(db gorp.SqlExecutor, appID int64) (int64, error) {
+	var max int64
+	err := loadColumn(db, appID, columnMaxConcurrentRuns, &max)
+	return max, err
+}
+
+// LockConcurrentRuns locks the given applications that have a concurrent runs limit, in ascending id order, see
+// LockApplications. It should be called before the first write of a workflow run transaction with all the
+// applications of the run, so ConcurrentRunsReached can't be raced by another run.
+func LockConcurrentRuns(ctx context.Context, tx gorpmapper.SqlExecutorWithTx, appIDs []int64) error {
+	if len(appIDs) == 0 {
+		return nil
+	}
+	var ids []int64
+	if _, err := tx.Select(&ids, "SELECT id FROM application WHERE id = ANY($1) AND max_concurrent_runs > 0",
+		pq.Int64Array(appIDs)); err != nil {
+		return sdk.WrapError(err, "cannot load concurrent runs limits of applications %v", appIDs)
+	}
+	return LockApplications(ctx, tx, ids)
+}
+
+// ConcurrentRunsReached returns true if given node run of an application has to wait because the application already
+// has as many node runs as its limit, counting the building ones and the older waiting ones. The node run should be
+// left waiting and started again when another node run of the application ends.
+func ConcurrentRunsReached(tx gorp.SqlExecutor, appID, nodeRunID int64) (bool, error) {
+	max, err := LoadMaxConcurrentRuns(tx, appID)
+	if err != nil {
+		return false, err
+	}
+	if max == 0 {
+		return false, nil
+	}
+	n, err := tx.SelectInt(`
+	SELECT COUNT(1)
+	FROM workflow_node_run
+	WHERE application_id = $1
+	AND (
+		(id < $2 AND status = $3)
+		OR
+		(id <> $2 AND status = $4)
+	)`, appID, nodeRunID, sdk.StatusWaiting, sdk.StatusBuilding)
+	if err != nil {
+		return false, sdk.WrapError(err, "cannot count running node runs for application %d", appID)
+	}
+	return n >= max, nil
+}
@@ -0,0 +1,34 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestConcurrentRunsReached(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	// Unlimited
+	reached, err := application.ConcurrentRunsReached(db, app.ID, 1)
+	require.NoError(t, err)
+	require.False(t, reached)
+
+	// No node run is waiting or building for a new application
+	require.NoError(t, application.SetMaxConcurrentRuns(db, app.ID, 1))
+	require.NoError(t, application.LockConcurrentRuns(context.TODO(), db, []int64{app.ID}))
+	reached, err = application.ConcurrentRunsReached(db, app.ID, 1)
+	require.NoError(t, err)
+	require.False(t, reached)
+}
@@ -0,0 +1,202 @@
+package application_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+// runConcurrently runs each given function in its own transaction at the same time and returns their errors.
+func runConcurrently(db *gorp.DbMap, fs ...func(tx gorpmapper.SqlExecutorWithTx) error) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(fs))
+	for i := range fs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, err := db.Begin()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer tx.Rollback() // nolint
+			if err := fs[i](tx); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = tx.Commit()
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestConcurrentUpdateAndRename(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{
+		Name:               "my-app",
+		RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", User: "user", Password: "my-password"},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	var fs []func(tx gorpmapper.SqlExecutorWithTx) error
+	for i := 0; i < 5; i++ {
+		i := i
+		fs = append(fs, func(tx gorpmapper.SqlExecutorWithTx) error {
+			a, err := application.LoadByID(tx, app.ID)
+			if err != nil {
+				return err
+			}
+			a.Description = fmt.Sprintf("description %d", i)
+			if i == 0 {
+				a.Name = "my-renamed-app"
+			}
+			return application.Update(context.TODO(), tx, a)
+		})
+	}
+	for _, err := range runConcurrently(db.DbMap, fs...) {
+		require.NoError(t, err)
+	}
+
+	// The last write wins and the application is still readable with its secret
+	res, err := application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Contains(t, []string{"my-app", "my-renamed-app"}, res.Name)
+	require.Equal(t, "my-password", res.RepositoryStrategy.Password)
+}
+
+func TestConcurrentUpdateColumnsAndRename(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	stale, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	renamed, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	renamed.Name = "my-renamed-app"
+	require.NoError(t, application.Update(context.TODO(), db, renamed))
+
+	// Signing the stale application would corrupt the renamed row
+	stale.Description = "my description"
+	err = application.UpdateColumns(application.ContextWithMigration(context.TODO()), db, stale, func(col *gorp.ColumnMap) bool {
+		return col.ColumnName == "description"
+	})
+	require.True(t, sdk.ErrorIs(err, sdk.ErrConflictData))
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-renamed-app", res.Name)
+	require.Empty(t, res.Description)
+}
+
+func TestConcurrentVariableImports(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+	u, _ := assets.InsertLambdaUser(t, db)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name:      "my-app",
+		Variables: []sdk.ApplicationVariable{{Name: "my-var", Type: sdk.StringVariable, Value: "my-value"}},
+	})
+
+	var fs []func(tx gorpmapper.SqlExecutorWithTx) error
+	for i := 0; i < 5; i++ {
+		vars := []sdk.Variable{{Name: fmt.Sprintf("my-var%d", i), Type: sdk.StringVariable, Value: "value"}}
+		fs = append(fs, func(tx gorpmapper.SqlExecutorWithTx) error {
+			return application.ImportVariables(context.TODO(), tx, app.ID, vars, application.ImportModeError, u)
+		})
+	}
+	fs = append(fs, func(tx gorpmapper.SqlExecutorWithTx) error {
+		return application.RenameVariable(context.TODO(), tx, app.ID, "my-var", "my-renamed-var", u)
+	})
+	for _, err := range runConcurrently(db.DbMap, fs...) {
+		require.NoError(t, err)
+	}
+
+	_, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	vars, err := application.LoadAllVariables(db, app.ID)
+	require.NoError(t, err)
+	names := make([]string, len(vars))
+	for i := range vars {
+		names[i] = vars[i].Name
+	}
+	require.ElementsMatch(t, []string{"my-renamed-var", "my-var0", "my-var1", "my-var2", "my-var3", "my-var4"}, names)
+}
+
+func TestUpdateWithETag(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	a, etag, err := application.LoadByIDWithETag(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	b, _, err := application.LoadByIDWithETag(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+
+	a.Description = "description a"
+	require.NoError(t, application.UpdateWithETag(context.TODO(), db, a, etag))
+
+	// Second update was loaded before the first one
+	b.Description = "description b"
+	err = application.UpdateWithETag(context.TODO(), db, b, etag)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrConflictData))
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "description a", res.Description)
+}
+
+func TestConcurrentInsertVariableMaxVariables(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	application.SetMaxVariables(2)
+	defer application.SetMaxVariables(0)
+
+	var fs []func(tx gorpmapper.SqlExecutorWithTx) error
+	for i := 0; i < 5; i++ {
+		i := i
+		fs = append(fs, func(tx gorpmapper.SqlExecutorWithTx) error {
+			v := sdk.ApplicationVariable{Name: fmt.Sprintf("var%d", i), Type: sdk.StringVariable, Value: "value"}
+			return application.InsertVariable(context.TODO(), tx, app.ID, &v, u)
+		})
+	}
+	var inserted int
+	for _, err := range runConcurrently(db.DbMap, fs...) {
+		if err == nil {
+			inserted++
+			continue
+		}
+		require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+	}
+	require.Equal(t, 2, inserted)
+
+	count, err := application.LoadVariableCount(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
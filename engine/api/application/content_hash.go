@@ -0,0 +1,165 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// applicationContent are the fields of an application covered by the content hash, in a stable order.
+type applicationContent struct {
+	Name               string       `json:"name"`
+	Description        string       `json:"description"`
+	Icon               string       `json:"icon"`
+	VCSServer          string       `json:"vcs_server"`
+	RepositoryFullname string       `json:"repository_fullname"`
+	ConnectionType     string       `json:"connection_type"`
+	SSHKey             string       `json:"ssh_key"`
+	User               string       `json:"user"`
+	Branch             string       `json:"branch"`
+	DefaultBranch      string       `json:"default_branch"`
+	PGPKey             string       `json:"pgp_key"`
+	PasswordRef        string       `json:"password_ref"`
+	Metadata           sdk.Metadata `json:"metadata"`
+	FromRepository     string       `json:"from_repository"`
+}
+
+// contentHash returns the sha256 of the fields written by Insert and Update. Secrets, the last modification date and
+// the flags that have their own setter are not part of the hash.
+func contentHash(app sdk.Application) (string, error) {
+	c := applicationContent{
+		Name:               app.Name,
+		Description:        app.Description,
+		Icon:               app.Icon,
+		VCSServer:          app.VCSServer,
+		RepositoryFullname: app.RepositoryFullname,
+		ConnectionType:     app.RepositoryStrategy.ConnectionType,
+		SSHKey:             app.RepositoryStrategy.SSHKey,
+		User:               app.RepositoryStrategy.User,
+		Branch:             app.RepositoryStrategy.Branch,
+		DefaultBranch:      app.RepositoryStrategy.DefaultBranch,
+		PGPKey:             app.RepositoryStrategy.PGPKey,
+		PasswordRef:        app.RepositoryStrategy.PasswordRef,
+		FromRepository:     app.FromRepository,
+	}
+	if len(app.Metadata) > 0 {
+		c.Metadata = app.Metadata
+	}
+	// Map keys are sorted by the json encoder
+	btes, err := json.Marshal(c)
+	if err != nil {
+		return "", sdk.WithStack(err)
+	}
+	sum := sha256.Sum256(btes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// setContentHash stores the content hash of given application, the column is not part of the signed data.
+func setContentHash(db gorp.SqlExecutor, app sdk.Application) error {
+	hash, err := contentHash(app)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec("UPDATE application SET content_hash = $2 WHERE id = $1", app.ID, hash); err != nil {
+		return sdk.WrapError(err, "cannot set content hash for application %d", app.ID)
+	}
+	return nil
+}
+
+// setContentHashes stores the content hash of given applications computed from their data in database, it is used
+// after writes that only update some columns. Applications with an invalid signature are ignored.
+func setContentHashes(ctx context.Context, db gorp.SqlExecutor, ids []int64) error {
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.id = ANY($1)`).Args(pq.Int64Array(ids))
+	apps, err := getAllWithClearVCS(ctx, db, nil, query)
+	if err != nil {
+		return err
+	}
+	hashIDs := make([]int64, 0, len(apps))
+	hashes := make([]string, 0, len(apps))
+	for _, app := range apps {
+		hash, err := contentHash(app)
+		if err != nil {
+			return err
+		}
+		hashIDs = append(hashIDs, app.ID)
+		hashes = append(hashes, hash)
+	}
+	if _, err := db.WithContext(ctx).Exec(`
+	UPDATE application SET content_hash = data.hash
+	FROM unnest($1::bigint[], $2::text[]) AS data(id, hash)
+	WHERE application.id = data.id`, pq.Int64Array(hashIDs), pq.StringArray(hashes)); err != nil {
+		return sdk.WrapError(err, "cannot set content hashes")
+	}
+	return nil
+}
+
+// LoadByIDWithHash returns an application and the hash of its content, the hash changes only when a field covered by
+// it changes. The hash of an application not written since the column was added is computed from the loaded data.
+func LoadByIDWithHash(ctx context.Context, db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.Application, string, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.id = $1`).Args(id)
+	app, err := get(ctx, db, "", opts, query)
+	if err != nil {
+		return nil, "", err
+	}
+	var hash sql.NullString
+	if err := db.SelectOne(&hash, "SELECT content_hash FROM application WHERE id = $1", id); err != nil {
+		return nil, "", sdk.WrapError(err, "cannot load content hash for application %d", id)
+	}
+	if hash.Valid {
+		return app, hash.String, nil
+	}
+	h, err := contentHash(*app)
+	if err != nil {
+		return nil, "", err
+	}
+	return app, h, nil
+}
+
+// LoadMeta returns if an application exists in given project, its last modification date and an etag computed from
+// its row version, with a single query and without decryption.
+func LoadMeta(db gorp.SqlExecutor, projectID int64, name string) (bool, time.Time, string, error) {
+	var id int64
+	var lastModified time.Time
+	var version string
+	if err := db.QueryRow("SELECT id, last_modified, xmin::text FROM application WHERE project_id = $1 AND name = $2",
+		projectID, name).Scan(&id, &lastModified, &version); err != nil {
+		if err == sql.ErrNoRows {
+			return false, time.Time{}, "", nil
+		}
+		return false, time.Time{}, "", sdk.WrapError(err, "cannot load meta of application %s", name)
+	}
+	return true, lastModified, applicationETag(id, version), nil
+}
+
+// applicationETag returns the etag of an application from its row version (xmin), it changes on any write of the
+// row, including secrets, protected columns and other columns that are not part of the content hash.
+func applicationETag(id int64, version string) string {
+	return fmt.Sprintf("\"%d-%s\"", id, version)
+}
+
+func loadETag(db gorp.SqlExecutor, id int64) (string, error) {
+	var version string
+	if err := db.QueryRow("SELECT xmin::text FROM application WHERE id = $1", id).Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return "", sdk.NewErrorFrom(sdk.ErrNotFound, "application %d not found", id)
+		}
+		return "", sdk.WrapError(err, "cannot load etag of application %d", id)
+	}
+	return applicationETag(id, version), nil
+}
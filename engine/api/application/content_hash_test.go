@@ -0,0 +1,87 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadByIDWithHash(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{
+		Name:               "my-app",
+		RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", User: "user", Password: "pwd"},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	res, hash1, err := application.LoadByIDWithHash(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Len(t, hash1, 64)
+
+	// The hash doesn't change if only secrets or the last modification date change
+	res.RepositoryStrategy.Password = "other-pwd"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	res, hash2, err := application.LoadByIDWithHash(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, hash1, hash2)
+
+	res.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	_, hash3, err := application.LoadByIDWithHash(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, hash2, hash3)
+
+	// The hash of an application written before the column was added is computed
+	_, err = db.Exec("UPDATE application SET content_hash = NULL WHERE id = $1", app.ID)
+	require.NoError(t, err)
+	_, hash4, err := application.LoadByIDWithHash(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, hash3, hash4)
+}
+
+func TestLoadMeta(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	found, lastModified, etag1, err := application.LoadMeta(db, proj.ID, "my-app")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.False(t, lastModified.IsZero())
+	require.NotEmpty(t, etag1)
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	res.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	found, _, etag2, err := application.LoadMeta(db, proj.ID, "my-app")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NotEqual(t, etag1, etag2)
+	_, etag, err := application.LoadByIDWithETag(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, etag2, etag)
+
+	_, err = db.Exec("UPDATE application SET content_hash = NULL WHERE id = $1", app.ID)
+	require.NoError(t, err)
+	found, _, etag3, err := application.LoadMeta(db, proj.ID, "my-app")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NotEmpty(t, etag3)
+
+	found, _, _, err = application.LoadMeta(db, proj.ID, "unknown")
+	require.NoError(t, err)
+	require.False(t, found)
+}
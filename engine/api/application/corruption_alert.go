@@ -0,0 +1,49 @@
+package application
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// CorruptionAlerter is notified when an application with an invalid signature is detected.
+type CorruptionAlerter interface {
+	AlertCorruption(ctx context.Context, appID int64)
+}
+
+// corruptionAlert holds the optional alerter and the detections already alerted, disabled by default.
+var corruptionAlert = struct {
+	sync.RWMutex
+	alerter CorruptionAlerter
+	seen    *gocache.Cache
+}{}
+
+// SetCorruptionAlerter sets the alerter notified the first time a corrupted application is detected
+// within given window, repeated detections in the window are ignored. Given nil alerter disables alerts.
+func SetCorruptionAlerter(alerter CorruptionAlerter, window time.Duration) {
+	corruptionAlert.Lock()
+	defer corruptionAlert.Unlock()
+	corruptionAlert.alerter = alerter
+	if alerter == nil {
+		corruptionAlert.seen = nil
+		return
+	}
+	corruptionAlert.seen = gocache.New(window, 2*window)
+}
+
+func alertCorruption(ctx context.Context, appID int64) {
+	corruptionAlert.RLock()
+	alerter, seen := corruptionAlert.alerter, corruptionAlert.seen
+	corruptionAlert.RUnlock()
+	if alerter == nil {
+		return
+	}
+	// Add fails if the application was already alerted in the window
+	if err := seen.Add(strconv.FormatInt(appID, 10), struct{}{}, gocache.DefaultExpiration); err != nil {
+		return
+	}
+	alerter.AlertCorruption(ctx, appID)
+}
@@ -0,0 +1,51 @@
+package application_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+type testCorruptionAlerter struct {
+	sync.Mutex
+	alerts []int64
+}
+
+func (a *testCorruptionAlerter) AlertCorruption(_ context.Context, appID int64) {
+	a.Lock()
+	defer a.Unlock()
+	a.alerts = append(a.alerts, appID)
+}
+
+func TestCorruptionAlerter(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	alerter := new(testCorruptionAlerter)
+	application.SetCorruptionAlerter(alerter, time.Hour)
+	defer application.SetCorruptionAlerter(nil, 0)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	_, err := db.Exec("UPDATE application SET sig = $1 WHERE id = $2", []byte("corrupted"), app.ID)
+	require.NoError(t, err)
+
+	_, err = application.LoadByID(db, app.ID)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+	apps, err := application.LoadAll(db, proj.Key)
+	require.NoError(t, err)
+	require.Len(t, apps, 0)
+
+	require.Equal(t, []int64{app.ID}, alerter.alerts)
+}
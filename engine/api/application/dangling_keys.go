@@ -0,0 +1,168 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+// DanglingKeyRef is a reference from an application to a key that does not exist.
+type DanglingKeyRef struct {
+	ApplicationID   int64  `json:"application_id"`
+	ApplicationName string `json:"application_name"`
+	// Field is the application field that holds the reference, "vcs_strategy.ssh_key", "vcs_strategy.pgp_key"
+	// or the name of the variable.
+	Field   string `json:"field"`
+	KeyName string `json:"key_name"`
+}
+
+// FindDanglingKeyRefs returns the references to missing keys of all the applications of given project.
+// A reference is valid if it matches a key of the project or a key of the application. References are read
+// from the vcs strategy and from the ssh-key and pgp-key variables. Nothing is written.
+func FindDanglingKeyRefs(ctx context.Context, db gorp.SqlExecutor, projectID int64) ([]DanglingKeyRef, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+
+	var projectKeyNames []string
+	if _, err := db.Select(&projectKeyNames, "SELECT name FROM project_key WHERE project_id = $1", projectID); err != nil {
+		return nil, sdk.WrapError(err, "cannot load keys of project %d", projectID)
+	}
+
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	ORDER BY application.name ASC`).Args(projectID)
+	apps, err := getAll(ctx, db, []LoadOptionFunc{LoadOptions.WithKeys, LoadOptions.WithVariables}, query)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []DanglingKeyRef{}
+	for _, app := range apps {
+		keyNames := make(map[string]struct{}, len(projectKeyNames)+len(app.Keys))
+		for _, n := range projectKeyNames {
+			keyNames[n] = struct{}{}
+		}
+		for _, k := range app.Keys {
+			keyNames[k.Name] = struct{}{}
+		}
+		check := func(field, keyName string) {
+			if keyName == "" {
+				return
+			}
+			if _, has := keyNames[keyName]; !has {
+				refs = append(refs, DanglingKeyRef{
+					ApplicationID:   app.ID,
+					ApplicationName: app.Name,
+					Field:           field,
+					KeyName:         keyName,
+				})
+			}
+		}
+
+		check("vcs_strategy.ssh_key", app.RepositoryStrategy.SSHKey)
+		check("vcs_strategy.pgp_key", app.RepositoryStrategy.PGPKey)
+		for _, v := range app.Variables {
+			switch v.Type {
+			case sdk.KeySSHParameter, sdk.KeyPGPParameter:
+				check(v.Name, v.Value)
+			}
+		}
+	}
+	return refs, nil
+}
+
+// RepointKey replaces the references to key oldKeyName by newKeyName in the vcs strategy and the ssh-key and pgp-key
+// variables of all the applications of given project, and returns the number of changed applications. The new key
+// must be a key of the project or of each changed application. Given db should be a transaction so nothing is written
+// if an application can't be changed. Changed applications are updated so they are signed again, and changed
+// variables are audited as updated by given user.
+func RepointKey(ctx context.Context, db gorpmapper.SqlExecutorWithTx, projectID int64, oldKeyName, newKeyName string, u sdk.Identifiable) (int64, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return 0, err
+	}
+	if oldKeyName == "" || newKeyName == "" {
+		return 0, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid empty key name")
+	}
+	if oldKeyName == newKeyName {
+		return 0, nil
+	}
+
+	var appIDs []int64
+	if _, err := db.Select(&appIDs, "SELECT id FROM application WHERE project_id = $1", projectID); err != nil {
+		return 0, sdk.WrapError(err, "cannot load applications of project %d", projectID)
+	}
+	if err := LockApplications(ctx, db, appIDs); err != nil {
+		return 0, err
+	}
+
+	count, err := db.SelectInt("SELECT COUNT(1) FROM project_key WHERE project_id = $1 AND name = $2", projectID, newKeyName)
+	if err != nil {
+		return 0, sdk.WrapError(err, "cannot check key %s of project %d", newKeyName, projectID)
+	}
+	isProjectKey := count > 0
+
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	ORDER BY application.id ASC`).Args(projectID)
+	apps, err := getAll(ctx, db, []LoadOptionFunc{LoadOptions.WithKeys, LoadOptions.WithVariables}, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for i := range apps {
+		app := &apps[i]
+
+		var vars []sdk.ApplicationVariable
+		for _, v := range app.Variables {
+			if (v.Type == sdk.KeySSHParameter || v.Type == sdk.KeyPGPParameter) && v.Value == oldKeyName {
+				vars = append(vars, v)
+			}
+		}
+		inStrategy := app.RepositoryStrategy.SSHKey == oldKeyName || app.RepositoryStrategy.PGPKey == oldKeyName
+		if !inStrategy && len(vars) == 0 {
+			continue
+		}
+
+		if !isProjectKey && !hasApplicationKey(*app, newKeyName) {
+			return 0, sdk.NewErrorFrom(sdk.ErrWrongRequest, "key %s not found for application %s", newKeyName, app.Name)
+		}
+
+		for j := range vars {
+			before := vars[j]
+			vars[j].Value = newKeyName
+			if err := UpdateVariable(ctx, db, app.ID, &vars[j], &before, u); err != nil {
+				return 0, sdk.WrapError(err, "cannot update variable %s of application %s", vars[j].Name, app.Name)
+			}
+		}
+		if app.RepositoryStrategy.SSHKey == oldKeyName {
+			app.RepositoryStrategy.SSHKey = newKeyName
+		}
+		if app.RepositoryStrategy.PGPKey == oldKeyName {
+			app.RepositoryStrategy.PGPKey = newKeyName
+		}
+		if err := Update(ctx, db, app); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+func hasApplicationKey(app sdk.Application, keyName string) bool {
+	for _, k := range app.Keys {
+		if k.Name == keyName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/keys"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestFindDanglingKeyRefs(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app1 := sdk.Application{
+		Name: "my-app1",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "ssh",
+			SSHKey:         "app-ssh",
+		},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	k := &sdk.ApplicationKey{
+		Name:          "app-ssh",
+		Type:          sdk.KeyTypeSSH,
+		ApplicationID: app1.ID,
+	}
+	kssh, err := keys.GenerateSSHKey(k.Name)
+	require.NoError(t, err)
+	k.Public = kssh.Public
+	k.Private = kssh.Private
+	k.KeyID = kssh.KeyID
+	require.NoError(t, application.InsertKey(context.TODO(), db, k))
+
+	app2 := sdk.Application{
+		Name: "my-app2",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "ssh",
+			SSHKey:         "app-ssh",
+			PGPKey:         "proj-missing",
+		},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	refs, err := application.FindDanglingKeyRefs(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, []application.DanglingKeyRef{
+		{ApplicationID: app2.ID, ApplicationName: app2.Name, Field: "vcs_strategy.ssh_key", KeyName: "app-ssh"},
+		{ApplicationID: app2.ID, ApplicationName: app2.Name, Field: "vcs_strategy.pgp_key", KeyName: "proj-missing"},
+	}, refs)
+}
+
+func TestRepointKey(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	insertKey := func(appID int64, name string) {
+		kssh, err := keys.GenerateSSHKey(name)
+		require.NoError(t, err)
+		require.NoError(t, application.InsertKey(context.TODO(), db, &sdk.ApplicationKey{
+			Name:          name,
+			Type:          sdk.KeyTypeSSH,
+			ApplicationID: appID,
+			Public:        kssh.Public,
+			Private:       kssh.Private,
+			KeyID:         kssh.KeyID,
+		}))
+	}
+
+	app1 := sdk.Application{
+		Name:               "my-app1",
+		RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "ssh", SSHKey: "old-ssh"},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	insertKey(app1.ID, "old-ssh")
+	insertKey(app1.ID, "new-ssh")
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app1.ID, &sdk.ApplicationVariable{Name: "deploy-key", Type: sdk.KeySSHParameter, Value: "old-ssh"}, u))
+
+	app2 := sdk.Application{Name: "my-app2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	n, err := application.RepointKey(context.TODO(), db, proj.ID, "old-ssh", "new-ssh", u)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	res, err := application.LoadByID(db, app1.ID, application.LoadOptions.WithVariables)
+	require.NoError(t, err)
+	require.Equal(t, "new-ssh", res.RepositoryStrategy.SSHKey)
+	require.Len(t, res.Variables, 1)
+	require.Equal(t, "new-ssh", res.Variables[0].Value)
+	audits, err := application.LoadVariableAudits(db, app1.ID, res.Variables[0].ID)
+	require.NoError(t, err)
+	require.Len(t, audits, 1)
+	require.Equal(t, u.GetUsername(), audits[0].Author)
+	require.NotNil(t, audits[0].VariableBefore)
+	require.Equal(t, "old-ssh", audits[0].VariableBefore.Value)
+
+	// The new key should exist for each changed application
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app2.ID, &sdk.ApplicationVariable{Name: "deploy-key", Type: sdk.KeySSHParameter, Value: "new-ssh"}, u))
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback() // nolint
+	_, err = application.RepointKey(context.TODO(), tx, proj.ID, "new-ssh", "other-ssh", u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+}
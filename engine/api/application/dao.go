@@ -3,6 +3,12 @@ package application
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-gorp/gorp"
@@ -40,6 +46,15 @@ var LoadOptions = struct {
 	WithClearDeploymentStrategies  LoadOptionFunc
 	WithVulnerabilities            LoadOptionFunc
 	WithIcon                       LoadOptionFunc
+	WithBestEffort                 LoadOptionFunc
+	WithWebhooks                   LoadOptionFunc
+	WithAccessTracking             LoadOptionFunc
+	WithPipelines                  LoadOptionFunc
+	WithExistingProject            LoadOptionFunc
+
+	// WithVariablesWithClearPasswordSkipUndecryptable is WithVariablesWithClearPassword except that a secret that
+	// can't be decrypted is marked as undecryptable instead of failing the load.
+	WithVariablesWithClearPasswordSkipUndecryptable LoadOptionFunc
 }{
 	Default:                        &loadDefaultDependencies,
 	WithVariables:                  &loadVariables,
@@ -50,17 +65,288 @@ var LoadOptions = struct {
 	WithClearDeploymentStrategies:  &loadDeploymentStrategiesWithClearPassword,
 	WithVulnerabilities:            &loadVulnerabilities,
 	WithIcon:                       &loadIcon,
+	WithBestEffort:                 &loadBestEffort,
+	WithWebhooks:                   &loadWebhooks,
+	WithAccessTracking:             &loadAccessTracking,
+	WithPipelines:                  &loadPipelines,
+	WithExistingProject:            &loadExistingProject,
+
+	WithVariablesWithClearPasswordSkipUndecryptable: &loadVariablesWithClearPasswordSkipUndecryptable,
 }
 
-// Exists checks if an application given its name exists
+// Exists checks if an application given its name exists.
+// Result can be cached if EnableExistsCache was called.
 func Exists(db gorp.SqlExecutor, projectKey, appName string) (bool, error) {
+	if exists, has := getExistsCache(projectKey, appName); has {
+		return exists, nil
+	}
 	count, err := db.SelectInt("SELECT count(1) FROM application join project ON project.id = application.project_id WHERE project.projectkey = $1 AND application.name = $2", projectKey, appName)
 	if err != nil {
 		return false, err
 	}
+	setExistsCache(projectKey, appName, count == 1)
 	return count == 1, nil
 }
 
+// ExistsInProjects returns for each given project id if an application with given name exists in it.
+func ExistsInProjects(db gorp.SqlExecutor, projectIDs []int64, appName string) (map[int64]bool, error) {
+	res := make(map[int64]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		res[id] = false
+	}
+	if len(projectIDs) == 0 {
+		return res, nil
+	}
+	var ids []int64
+	query := "SELECT project.id FROM project JOIN application ON application.project_id = project.id WHERE project.id = ANY($1) AND application.name = $2"
+	if _, err := db.Select(&ids, query, pq.Int64Array(projectIDs), appName); err != nil {
+		return nil, sdk.WithStack(err)
+	}
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// LoadRepositoryIndex returns for given project the ids of the applications by repository fullname.
+// Applications without repository are skipped.
+func LoadRepositoryIndex(db gorp.SqlExecutor, projectID int64) (map[string][]int64, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID         int64  `db:"id"`
+		Repository string `db:"repo_fullname"`
+	}
+	query := `
+	SELECT id, repo_fullname
+	FROM application
+	WHERE project_id = $1
+	AND COALESCE(repo_fullname, '') <> ''
+	ORDER BY repo_fullname, id`
+	if _, err := db.Select(&rows, query, projectID); err != nil {
+		return nil, sdk.WrapError(err, "cannot load repository index for project %d", projectID)
+	}
+	res := make(map[string][]int64)
+	for _, r := range rows {
+		res[r.Repository] = append(res[r.Repository], r.ID)
+	}
+	return res, nil
+}
+
+// CountByRepository returns the number of applications of given project by as code repository.
+// Applications created manually are skipped.
+func CountByRepository(db gorp.SqlExecutor, projectID int64) (map[string]int64, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Repository string `db:"from_repository"`
+		Count      int64  `db:"count"`
+	}
+	query := `
+	SELECT from_repository, COUNT(1) AS count
+	FROM application
+	WHERE project_id = $1
+	AND COALESCE(from_repository, '') <> ''
+	GROUP BY from_repository`
+	if _, err := db.Select(&rows, query, projectID); err != nil {
+		return nil, sdk.WrapError(err, "cannot count applications by repository for project %d", projectID)
+	}
+	res := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		res[r.Repository] = r.Count
+	}
+	return res, nil
+}
+
+// DetachFromRepository removes the as code repository of given applications of a project so they are managed from
+// the UI, and returns the number of detached applications. All applications are detached in a single statement,
+// the repository is not part of the signed data so signatures stay valid. Applications are locked in ascending id
+// order first so concurrent bulk operations on overlapping sets can't deadlock on row locks. Read only applications
+// are skipped, the content hash is refreshed and an update change is recorded for each detached application.
+func DetachFromRepository(ctx context.Context, db gorpmapper.SqlExecutorWithTx, projectID int64, appIDs []int64) (int64, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return 0, err
+	}
+	if err := checkNotReadOnlyLoad(ctx); err != nil {
+		return 0, err
+	}
+	if err := LockApplications(ctx, db, appIDs); err != nil {
+		return 0, err
+	}
+	var candidates []struct {
+		ID       int64 `db:"id"`
+		ReadOnly bool  `db:"read_only"`
+	}
+	if _, err := db.WithContext(ctx).Select(&candidates, `
+	SELECT id, read_only FROM application
+	WHERE project_id = $1 AND id = ANY($2) AND from_repository <> ''`, projectID, pq.Int64Array(appIDs)); err != nil {
+		return 0, sdk.WrapError(err, "cannot load applications %v", appIDs)
+	}
+	writableIDs := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		if checkWritable(ctx, c.ID, c.ReadOnly) == nil {
+			writableIDs = append(writableIDs, c.ID)
+		}
+	}
+	if len(writableIDs) == 0 {
+		return 0, nil
+	}
+	res, err := db.WithContext(ctx).Exec("UPDATE application SET from_repository = '' WHERE id = ANY($1)", pq.Int64Array(writableIDs))
+	if err != nil {
+		return 0, sdk.WrapError(err, "cannot detach applications %v from their repository", writableIDs)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, sdk.WithStack(err)
+	}
+	if err := setContentHashes(ctx, db, writableIDs); err != nil {
+		return 0, err
+	}
+	if err := insertUpdateChanges(ctx, db, writableIDs); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// CountBySource returns the number of applications of given project created from a repository or manually.
+func CountBySource(db gorp.SqlExecutor, projectID int64) (map[SourceFilter]int64, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		AsCode bool  `db:"ascode"`
+		Count  int64 `db:"count"`
+	}
+	query := `
+	SELECT COALESCE(from_repository, '') <> '' AS ascode, COUNT(1) AS count
+	FROM application
+	WHERE project_id = $1
+	GROUP BY ascode`
+	if _, err := db.Select(&rows, query, projectID); err != nil {
+		return nil, sdk.WrapError(err, "cannot count applications by source for project %d", projectID)
+	}
+	res := map[SourceFilter]int64{SourceAsCode: 0, SourceManual: 0}
+	for _, r := range rows {
+		if r.AsCode {
+			res[SourceAsCode] = r.Count
+		} else {
+			res[SourceManual] = r.Count
+		}
+	}
+	return res, nil
+}
+
+// CountByVCSHost returns the number of applications of given project by repository host.
+// The host is read from the as code repository url, else the vcs server name is used.
+// Applications without any repository are counted with an empty host.
+func CountByVCSHost(db gorp.SqlExecutor, projectID int64) (map[string]int64, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		FromRepository string `db:"from_repository"`
+		VCSServer      string `db:"vcs_server"`
+	}
+	query := "SELECT COALESCE(from_repository, '') AS from_repository, COALESCE(vcs_server, '') AS vcs_server FROM application WHERE project_id = $1"
+	if _, err := db.Select(&rows, query, projectID); err != nil {
+		return nil, sdk.WrapError(err, "cannot count applications by vcs host for project %d", projectID)
+	}
+	res := make(map[string]int64)
+	for _, r := range rows {
+		host := repositoryHost(r.FromRepository)
+		if host == "" {
+			host = r.VCSServer
+		}
+		res[host]++
+	}
+	return res, nil
+}
+
+// ConnectionTypeSecretRef is the connection type reported by CountByConnectionType for https strategies with a
+// password reference.
+const ConnectionTypeSecretRef = "secret-ref"
+
+func vcsConnectionType(s sdk.RepositoryStrategy) string {
+	if s.PasswordRef != "" {
+		return ConnectionTypeSecretRef
+	}
+	return s.ConnectionType
+}
+
+func hasVCSPassword(s sdk.RepositoryStrategy) bool {
+	return s.Password != "" || s.PasswordRef != ""
+}
+
+// setVCSStrategyColumns stores the connection type and if a password is set in clear next to the encrypted vcs
+// strategy so they can be read without decryption. The columns are not part of the signed data.
+func setVCSStrategyColumns(db gorp.SqlExecutor, appID int64, s sdk.RepositoryStrategy) error {
+	if _, err := db.Exec("UPDATE application SET vcs_connection_type = $2, vcs_password_set = $3 WHERE id = $1",
+		appID, vcsConnectionType(s), hasVCSPassword(s)); err != nil {
+		return sdk.WrapError(err, "cannot set vcs strategy columns for application %d", appID)
+	}
+	return nil
+}
+
+// CountByConnectionType returns the number of applications of given project by vcs connection type: ssh, https,
+// secret-ref, or empty without strategy. Applications not written since the connection type column was added are
+// decrypted to be counted.
+func CountByConnectionType(db gorp.SqlExecutor, projectID int64) (map[string]int64, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ConnectionType string `db:"connection_type"`
+		Count          int64  `db:"count"`
+	}
+	query := `
+	SELECT vcs_connection_type AS connection_type, COUNT(1) AS count
+	FROM application
+	WHERE project_id = $1 AND vcs_connection_type IS NOT NULL
+	GROUP BY vcs_connection_type`
+	if _, err := db.Select(&rows, query, projectID); err != nil {
+		return nil, sdk.WrapError(err, "cannot count applications by connection type for project %d", projectID)
+	}
+	res := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		res[r.ConnectionType] = r.Count
+	}
+
+	apps, err := getAll(context.Background(), db, nil, gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE project_id = $1 AND vcs_connection_type IS NULL`).Args(projectID))
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range apps {
+		res[vcsConnectionType(app.RepositoryStrategy)]++
+	}
+	return res, nil
+}
+
+// repositoryHost returns the host of an http or ssh repository url (ex: git@github.com:ovh/cds.git).
+func repositoryHost(repo string) string {
+	if repo == "" {
+		return ""
+	}
+	if strings.Contains(repo, "://") {
+		u, err := url.Parse(repo)
+		if err != nil {
+			return ""
+		}
+		return u.Hostname()
+	}
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[i+1:]
+	}
+	if i := strings.Index(repo, ":"); i >= 0 {
+		return repo[:i]
+	}
+	return ""
+}
+
 // LoadByName load an application from DB
 func LoadByName(db gorp.SqlExecutor, projectKey, appName string, opts ...LoadOptionFunc) (*sdk.Application, error) {
 	query := gorpmapping.NewQuery(`
@@ -72,32 +358,132 @@ func LoadByName(db gorp.SqlExecutor, projectKey, appName string, opts ...LoadOpt
 	return get(context.Background(), db, projectKey, opts, query)
 }
 
-// LoadByNameWithClearVCSStrategyPassword load an application from DB
-func LoadByNameWithClearVCSStrategyPassword(db gorp.SqlExecutor, projectKey, appName string, opts ...LoadOptionFunc) (*sdk.Application, error) {
+// LoadByNameWithClearVCSStrategyPassword load an application from DB.
+// A password reference is resolved with the resolver set by SetSecretResolver.
+func LoadByNameWithClearVCSStrategyPassword(ctx context.Context, db gorp.SqlExecutor, projectKey, appName string, opts ...LoadOptionFunc) (*sdk.Application, error) {
 	query := gorpmapping.NewQuery(`
 		SELECT application.*
 		FROM application
 		JOIN project ON project.id = application.project_id
 		WHERE project.projectkey = $1
 		AND application.name = $2`).Args(projectKey, appName)
-	return getWithClearVCSStrategyPassword(context.Background(), db, projectKey, opts, query)
+	app, err := getWithClearVCSStrategyPassword(ctx, db, projectKey, opts, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveVCSStrategyPassword(ctx, app); err != nil {
+		return nil, err
+	}
+	auditVCSPasswordAccess(ctx, app.ID)
+	return app, nil
 }
 
-func LoadByIDWithClearVCSStrategyPassword(db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.Application, error) {
+// LoadByIDWithClearVCSStrategyPassword load an application from DB.
+// A password reference is resolved with the resolver set by SetSecretResolver.
+func LoadByIDWithClearVCSStrategyPassword(ctx context.Context, db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.Application, error) {
+	app, err := loadByIDWithClearVCSStrategyPassword(ctx, db, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveVCSStrategyPassword(ctx, app); err != nil {
+		return nil, err
+	}
+	auditVCSPasswordAccess(ctx, app.ID)
+	return app, nil
+}
+
+func loadByIDWithClearVCSStrategyPassword(ctx context.Context, db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.Application, error) {
 	query := gorpmapping.NewQuery(`
                 SELECT application.*
                 FROM application
                 WHERE application.id = $1`).Args(id)
-	return getWithClearVCSStrategyPassword(context.Background(), db, "", opts, query)
+	return getWithClearVCSStrategyPassword(ctx, db, "", opts, query)
+}
+
+// HasVCSPassword returns true if a vcs strategy password or password reference is configured for given application.
+// It is read from a clear column kept up to date by Insert and Update. An application not written since the column
+// was added is decrypted once to set it, the password is never returned so the access is not audited.
+func HasVCSPassword(db gorp.SqlExecutor, appID int64) (bool, error) {
+	var set sql.NullBool
+	if err := db.QueryRow("SELECT vcs_password_set FROM application WHERE id = $1", appID).Scan(&set); err != nil {
+		if err == sql.ErrNoRows {
+			return false, sdk.NewErrorFrom(sdk.ErrNotFound, "application %d not found", appID)
+		}
+		return false, sdk.WrapError(err, "cannot load vcs password flag for application %d", appID)
+	}
+	if set.Valid {
+		return set.Bool, nil
+	}
+	app, err := loadByIDWithClearVCSStrategyPassword(context.Background(), db, appID)
+	if err != nil {
+		return false, err
+	}
+	if err := setVCSStrategyColumns(db, appID, app.RepositoryStrategy); err != nil {
+		return false, err
+	}
+	return hasVCSPassword(app.RepositoryStrategy), nil
 }
 
 // LoadByID load an application from DB
+// Without load options, the application is read from the id cache if it is enabled and its row was not written since.
 func LoadByID(db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.Application, error) {
 	query := gorpmapping.NewQuery(`
                 SELECT application.*
                 FROM application
                 WHERE application.id = $1`).Args(id)
-	return get(context.Background(), db, "", opts, query)
+	if len(opts) > 0 || !isIDCacheEnabled() {
+		return get(context.Background(), db, "", opts, query)
+	}
+	// The version is loaded first so an application written while it is loaded is not cached with the new version
+	version, err := loadRowVersion(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if app, has := getIDCache(id, version); has {
+		return app, nil
+	}
+	app, err := get(context.Background(), db, "", nil, query)
+	if err != nil {
+		return nil, err
+	}
+	setIDCache(version, *app)
+	return app, nil
+}
+
+// LoadByIDWithETag returns an application and the same ETag as LoadMeta, it changes on each write of the application
+// row, access tracking included. Changes on variables, keys or deployment strategies don't change the ETag.
+// The ETag is loaded first so a write between the two queries makes UpdateWithETag fail instead of being lost.
+func LoadByIDWithETag(ctx context.Context, db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.Application, string, error) {
+	etag, err := loadETag(db, id)
+	if err != nil {
+		return nil, "", err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.id = $1`).Args(id)
+	app, err := get(ctx, db, "", opts, query)
+	if err != nil {
+		return nil, "", err
+	}
+	return app, etag, nil
+}
+
+// UpdateWithETag updates an application only if it was not modified since it was loaded with the given etag from
+// LoadByIDWithETag, sdk.ErrConflictData is returned otherwise. The application is locked until the end of the
+// transaction so the check and the update can't be interleaved with another write.
+func UpdateWithETag(ctx context.Context, db gorpmapper.SqlExecutorWithTx, app *sdk.Application, etag string) error {
+	if err := LockApplication(ctx, db, app.ID); err != nil {
+		return err
+	}
+	current, err := loadETag(db, app.ID)
+	if err != nil {
+		return err
+	}
+	if current != etag {
+		return sdk.NewErrorFrom(sdk.ErrConflictData, "application %d was modified since it was loaded", app.ID)
+	}
+	return Update(ctx, db, app)
 }
 
 // LoadByWorkflowID loads applications from database for a given workflow id
@@ -112,6 +498,98 @@ func LoadByWorkflowID(db gorp.SqlExecutor, workflowID int64) ([]sdk.Application,
 	return getAll(context.Background(), db, nil, query)
 }
 
+// recentlyModifiedMaxLimit caps the number of applications returned by LoadRecentlyModified.
+const recentlyModifiedMaxLimit = 100
+
+// LoadRecentlyModified returns at most limit applications of given project before given last modification date and
+// id, the most recently modified first, and true if more applications remain. The first page is loaded with the
+// current time and an id of 0 to include the applications modified at this time, the next one with the last modification date and id of
+// the last returned application so applications modified at the same time are not skipped. Limit is capped to 100.
+func LoadRecentlyModified(ctx context.Context, db gorp.SqlExecutor, projectID int64, beforeTime time.Time, beforeID int64, limit int, opts ...LoadOptionFunc) ([]sdk.Application, bool, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, false, err
+	}
+	if limit <= 0 {
+		return nil, false, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid limit %d", limit)
+	}
+	if limit > recentlyModifiedMaxLimit {
+		limit = recentlyModifiedMaxLimit
+	}
+	if beforeID <= 0 {
+		beforeID = math.MaxInt64
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	AND (application.last_modified, application.id) < ($2, $3)
+	ORDER BY application.last_modified DESC, application.id DESC
+	LIMIT $4`).Args(projectID, beforeTime, beforeID, limit+1)
+	apps, err := getAll(ctx, db, opts, query)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(apps) > limit
+	if hasMore {
+		apps = apps[:limit]
+	}
+	res := make([]sdk.Application, 0, len(apps))
+	for _, app := range apps {
+		if app.ID != 0 {
+			res = append(res, app)
+		}
+	}
+	return res, hasMore, nil
+}
+
+// usageMaxLimit caps the number of applications returned by LoadAllByUsageDesc.
+const usageMaxLimit = 100
+
+// LoadAllByUsageDesc returns at most limit applications of given project used in workflows, ordered by the number
+// of workflows that use them, the most used first. Limit is capped to 100.
+func LoadAllByUsageDesc(ctx context.Context, db gorp.SqlExecutor, projectID int64, limit int, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid limit %d", limit)
+	}
+	if limit > usageMaxLimit {
+		limit = usageMaxLimit
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	JOIN w_node_context ON w_node_context.application_id = application.id
+	JOIN w_node ON w_node.id = w_node_context.node_id
+	WHERE application.project_id = $1
+	GROUP BY application.id
+	ORDER BY COUNT(DISTINCT w_node.workflow_id) DESC, application.name ASC
+	LIMIT $2`).Args(projectID, limit)
+	return getAll(ctx, db, opts, query)
+}
+
+// LoadAllByMetadata returns the applications of given project that have given metadata value, sorted by name.
+// The query is a jsonb containment backed by a GIN index.
+func LoadAllByMetadata(ctx context.Context, db gorp.SqlExecutor, projectID int64, key, value string, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid empty metadata key")
+	}
+	filter, err := json.Marshal(sdk.Metadata{key: value})
+	if err != nil {
+		return nil, sdk.WithStack(err)
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1 AND application.metadata @> $2::jsonb
+	ORDER BY application.name ASC`).Args(projectID, string(filter))
+	return getAll(ctx, db, opts, query)
+}
+
 func get(ctx context.Context, db gorp.SqlExecutor, key string, opts []LoadOptionFunc, query gorpmapping.Query) (*sdk.Application, error) {
 	app, err := getWithClearVCSStrategyPassword(ctx, db, key, opts, query)
 	if err != nil {
@@ -119,6 +597,7 @@ func get(ctx context.Context, db gorp.SqlExecutor, key string, opts []LoadOption
 	}
 	app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
 	app.RepositoryStrategy.SSHKeyContent = ""
+	applyContextMaskingPolicy(ctx, app)
 	return app, nil
 }
 
@@ -138,13 +617,23 @@ func getWithClearVCSStrategyPassword(ctx context.Context, db gorp.SqlExecutor, k
 	}
 	if !isValid {
 		log.Error(context.Background(), "application.get> application %d data corrupted", dbApp.ID)
+		alertCorruption(ctx, dbApp.ID)
 		return nil, sdk.WithStack(sdk.ErrNotFound)
 	}
 	dbApp.ProjectKey = key
-	return unwrap(db, opts, &dbApp)
+	app, err := unwrap(ctx, db, opts, &dbApp)
+	if sdk.Cause(err) == errProjectNotFound {
+		return nil, sdk.NewErrorFrom(sdk.ErrNotFound, "project %d of application %d not found", dbApp.ProjectID, dbApp.ID)
+	}
+	return app, err
 }
 
-func unwrap(db gorp.SqlExecutor, opts []LoadOptionFunc, dbApp *dbApplication) (*sdk.Application, error) {
+// errProjectNotFound is returned by unwrap with LoadOptions.WithExistingProject if the project of the application
+// does not exist, see FindOrphanedByProject to list these applications.
+var errProjectNotFound = fmt.Errorf("project of the application not found")
+
+func unwrap(ctx context.Context, db gorp.SqlExecutor, opts []LoadOptionFunc, dbApp *dbApplication) (*sdk.Application, error) {
+	db = readOnlyLoadDB(ctx, db)
 	app := &dbApp.Application
 	if app.ProjectKey == "" {
 		pkey, errP := db.SelectStr("SELECT projectkey FROM project WHERE id = $1", app.ProjectID)
@@ -153,29 +642,111 @@ func unwrap(db gorp.SqlExecutor, opts []LoadOptionFunc, dbApp *dbApplication) (*
 		}
 		app.ProjectKey = pkey
 	}
+	if app.ProjectKey == "" && isExistingProject(opts) {
+		return nil, sdk.WithStack(errProjectNotFound)
+	}
 
+	bestEffort := isBestEffort(opts)
 	for _, f := range opts {
 		if err := (*f)(db, app); err != nil && sdk.Cause(err) != sql.ErrNoRows {
+			if bestEffort {
+				log.Warning(ctx, "application.unwrap> unable to load optional data for application %d: %v", app.ID, err)
+				continue
+			}
 			return nil, sdk.WrapError(err, "application.unwrap")
 		}
 	}
 	return app, nil
 }
 
-// Insert add an application id database
-func Insert(db gorpmapper.SqlExecutorWithTx, proj sdk.Project, app *sdk.Application) error {
+// warnRepositoryStrategy logs a warning for repository strategy that will not be usable at runtime.
+func warnRepositoryStrategy(app sdk.Application) {
+	if app.RepositoryStrategy.ConnectionType == "https" && app.RepositoryStrategy.User != "" && app.RepositoryStrategy.Password == "" && app.RepositoryStrategy.PasswordRef == "" {
+		log.Warning(context.Background(), "application %s: connection type https with user %s but without password", app.Name, app.RepositoryStrategy.User)
+	}
+}
+
+func checkProjectID(projectID int64) error {
+	if projectID <= 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid project id %d", projectID)
+	}
+	return nil
+}
+
+// Insert add an application id database, nothing is written if given context is done.
+// If the context holds an idempotency key already used for given project, the existing application is returned.
+func Insert(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Project, app *sdk.Application) error {
+	_, err := insert(ctx, db, proj, app)
+	return err
+}
+
+// insert is Insert that returns true if the application was not inserted because its idempotency key was already used.
+func insert(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Project, app *sdk.Application) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, sdk.WithStack(err)
+	}
+	if err := checkNotReadOnlyLoad(ctx); err != nil {
+		return false, err
+	}
+	if err := checkProjectID(proj.ID); err != nil {
+		return false, err
+	}
+	idemKey := IdempotencyKeyFromContext(ctx)
+	if idemKey != "" {
+		if len(idemKey) > idempotencyKeyMaxLength {
+			return false, sdk.NewErrorFrom(sdk.ErrWrongRequest, "idempotency key should not exceed %d characters", idempotencyKeyMaxLength)
+		}
+		existing, err := LoadByIdempotencyKey(db, proj.ID, idemKey)
+		if err != nil && !sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return false, err
+		}
+		if existing != nil {
+			*app = *existing
+			return true, nil
+		}
+	}
+	app.Description = sdk.RemoveControlCharacters(app.Description)
 	if err := app.IsValid(); err != nil {
-		return sdk.WrapError(err, "application is not valid")
+		return false, sdk.WrapError(err, "application is not valid")
+	}
+	if err := checkNamePolicy(app.Name); err != nil {
+		return false, err
 	}
+	if err := checkSecretResolver(*app); err != nil {
+		return false, err
+	}
+
+	warnRepositoryStrategy(*app)
 
 	app.ProjectID = proj.ID
 	app.ProjectKey = proj.Key
 	app.LastModified = time.Now()
+	// Protected columns can only be changed with their own setter, a new application starts with their defaults
+	app.ReadOnly = false
+	app.Frozen = false
+	app.RetentionDays = 0
+	app.MaxConcurrentRuns = 0
+	app.Color = ""
+	if app.RepositoryStrategy.PasswordRef != "" {
+		app.RepositoryStrategy.Password = ""
+	}
 	copyVCSStrategy := app.RepositoryStrategy
 
 	dbApp := dbApplication{Application: *app}
-	if err := gorpmapping.InsertAndSign(context.Background(), db, &dbApp); err != nil {
-		return sdk.WrapError(err, "application.Insert %s(%d)", app.Name, app.ID)
+	if err := gorpmapping.InsertAndSign(ctx, db, &dbApp); err != nil {
+		return false, sdk.WrapError(err, "application.Insert %s(%d)", app.Name, app.ID)
+	}
+	if err := setVCSStrategyColumns(db, dbApp.ID, copyVCSStrategy); err != nil {
+		return false, err
+	}
+	invalidateExistsCache(proj.Key)
+	if err := insertChange(db, sdk.ApplicationChangeInsert, dbApp.ID, dbApp.ProjectID, &dbApp.Application); err != nil {
+		return false, err
+	}
+	if idemKey != "" {
+		if err := insertIdempotencyKey(db, proj.ID, idemKey, dbApp.ID); err != nil {
+			return false, err
+		}
 	}
 	*app = dbApp.Application
 	// Reset the vcs_stragegy except the passowrd because it as been erased by the encryption layed
@@ -183,58 +754,197 @@ func Insert(db gorpmapper.SqlExecutorWithTx, proj sdk.Project, app *sdk.Applicat
 	app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
 	app.RepositoryStrategy.SSHKeyContent = ""
 
-	return nil
+	return false, setContentHash(db, *app)
+}
+
+// InsertResult is the confirmation of an application creation.
+// There is no creation date column on application, Created is the last modified date set at insert.
+// Replayed is true if the idempotency key of the context was already used and the existing application was returned.
+type InsertResult struct {
+	ID       int64     `json:"id"`
+	Created  time.Time `json:"created"`
+	Verified bool      `json:"verified"`
+	Replayed bool      `json:"replayed"`
+}
+
+// InsertWithResult inserts given application like Insert then reads the signed row back to check its signature.
+// An error is returned if the row can't be read back, Verified is false if its signature is invalid.
+// A replayed application was loaded with its signature checked, so it is not read back.
+func InsertWithResult(ctx context.Context, db gorpmapper.SqlExecutorWithTx, proj sdk.Project, app *sdk.Application) (*InsertResult, error) {
+	replayed, err := insert(ctx, db, proj, app)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return &InsertResult{
+			ID:       app.ID,
+			Created:  app.LastModified,
+			Verified: true,
+			Replayed: true,
+		}, nil
+	}
+
+	var dbApp dbApplication
+	query := gorpmapping.NewQuery("SELECT * FROM application WHERE id = $1").Args(app.ID)
+	found, err := gorpmapping.Get(ctx, db, query, &dbApp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, sdk.WrapError(sdk.ErrNotFound, "cannot read back application %d", app.ID)
+	}
+	isValid, err := gorpmapping.CheckSignature(dbApp, dbApp.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		log.Error(ctx, "application.InsertWithResult> application %d data corrupted", dbApp.ID)
+	}
+
+	return &InsertResult{
+		ID:       app.ID,
+		Created:  app.LastModified,
+		Verified: isValid,
+	}, nil
+}
+
+// ContextWithKeepEmptyVCSPassword returns a copy of the context that makes Update keep the stored vcs strategy
+// password when given application has an https strategy with an empty password. It should be used for callers that
+// always send full payloads without the password.
+func ContextWithKeepEmptyVCSPassword(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeepEmptyVCSPassword, true)
+}
+
+func hasKeepEmptyVCSPassword(ctx context.Context) bool {
+	keep, _ := ctx.Value(contextKeepEmptyVCSPassword).(bool)
+	return keep
 }
 
-// Update updates application id database
-func Update(db gorpmapper.SqlExecutorWithTx, app *sdk.Application) error {
-	if app.RepositoryStrategy.Password == sdk.PasswordPlaceholder {
-		appTmp, err := LoadByIDWithClearVCSStrategyPassword(db, app.ID)
+// Update updates application id database, nothing is written if given context is done.
+// The vcs strategy password is kept if it is the placeholder, or if it is empty for an https strategy and given
+// context was returned by ContextWithKeepEmptyVCSPassword. Otherwise the given password is stored, an empty one
+// removes the stored password. It returns sdk.ErrNotFound if no application has given id.
+func Update(ctx context.Context, db gorpmapper.SqlExecutorWithTx, app *sdk.Application) error {
+	if app.ID <= 0 {
+		return sdk.NewErrorFrom(sdk.ErrNotFound, "invalid application id %d", app.ID)
+	}
+	// Flags and secrets read below must not change before the row is written
+	if err := LockApplication(ctx, db, app.ID); err != nil {
+		return err
+	}
+	// Read only and frozen flags, retention days, max concurrent runs and color can only be changed with
+	// SetReadOnly, Freeze, Unfreeze, SetRetentionDays, SetMaxConcurrentRuns and SetColor
+	if err := loadProtectedColumns(db, app); err != nil {
+		return err
+	}
+	if err := checkWritable(ctx, app.ID, app.ReadOnly); err != nil {
+		return err
+	}
+
+	keepPassword := app.RepositoryStrategy.Password == sdk.PasswordPlaceholder ||
+		(app.RepositoryStrategy.Password == "" && app.RepositoryStrategy.ConnectionType == "https" && hasKeepEmptyVCSPassword(ctx))
+	// Vcs user and server can be masked by the caller masking policy
+	if keepPassword || app.RepositoryStrategy.User == sdk.PasswordPlaceholder || app.VCSServer == sdk.PasswordPlaceholder {
+		appTmp, err := loadByIDWithClearVCSStrategyPassword(ctx, db, app.ID)
 		if err != nil {
 			return err
 		}
-		app.RepositoryStrategy.Password = appTmp.RepositoryStrategy.Password
+		if keepPassword {
+			app.RepositoryStrategy.Password = appTmp.RepositoryStrategy.Password
+		}
+		if app.RepositoryStrategy.User == sdk.PasswordPlaceholder {
+			app.RepositoryStrategy.User = appTmp.RepositoryStrategy.User
+		}
+		if app.VCSServer == sdk.PasswordPlaceholder {
+			app.VCSServer = appTmp.VCSServer
+		}
 	}
-	if app.RepositoryStrategy.ConnectionType == "ssh" {
+	if app.RepositoryStrategy.ConnectionType == "ssh" || app.RepositoryStrategy.PasswordRef != "" {
 		app.RepositoryStrategy.Password = ""
 	}
 
 	var copyVCSStrategy = app.RepositoryStrategy
 
+	app.Description = sdk.RemoveControlCharacters(app.Description)
 	if err := app.IsValid(); err != nil {
 		return sdk.WrapError(err, "application is not valid")
 	}
+	if err := checkRenameNamePolicy(db, *app); err != nil {
+		return err
+	}
+	if err := checkSecretResolver(*app); err != nil {
+		return err
+	}
+	warnRepositoryStrategy(*app)
+	if err := ctx.Err(); err != nil {
+		return sdk.WithStack(err)
+	}
 	app.LastModified = time.Now()
 	dbApp := dbApplication{Application: *app}
-	if err := gorpmapping.UpdateAndSign(context.Background(), db, &dbApp); err != nil {
+	if err := gorpmapping.UpdateAndSign(ctx, db, &dbApp); err != nil {
+		// The mapper returns sdk.ErrNotFound if no row was updated
+		if sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "application %d not found", app.ID)
+		}
 		return sdk.WrapError(err, "application.Update %s(%d)", app.Name, app.ID)
 	}
+	if err := setVCSStrategyColumns(db, app.ID, copyVCSStrategy); err != nil {
+		return err
+	}
+	if err := insertChange(db, sdk.ApplicationChangeUpdate, app.ID, app.ProjectID, app); err != nil {
+		return err
+	}
+	// The application could have been renamed
+	if err := invalidateExistsCacheByApplicationID(db, app.ID); err != nil {
+		return err
+	}
 	// Reset the vcs_stragegy except the passowrd because it as been erased by the encryption layed
 	app.RepositoryStrategy = copyVCSStrategy
 	app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
 	app.RepositoryStrategy.SSHKeyContent = ""
-	return nil
+	return setContentHash(db, *app)
 }
 
-// LoadAll returns all applications
+// LoadAll returns all applications sorted by name
 func LoadAll(db gorp.SqlExecutor, key string, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	return LoadAllSorted(db, key, SortNameAsc, opts...)
+}
+
+// LoadAllSorted returns all applications with given sort, default sort is by name.
+func LoadAllSorted(db gorp.SqlExecutor, key string, sort Sort, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if key == "" {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid empty project key")
+	}
+	orderBy, err := sort.sql()
+	if err != nil {
+		return nil, err
+	}
 	query := gorpmapping.NewQuery(`
 	SELECT application.*
 	FROM application
 	JOIN project ON project.id = application.project_id
 	WHERE project.projectkey = $1
-	ORDER BY application.name ASC`).Args(key)
+	ORDER BY ` + orderBy).Args(key)
 
 	return getAll(context.Background(), db, opts, query)
 }
 
-// LoadAllByIDsWithDecryption returns all applications with clear vcs strategy
+// LoadAllByIDsWithDecryption returns all applications with clear vcs strategy, password references are resolved.
 func LoadAllByIDsWithDecryption(db gorp.SqlExecutor, ids []int64, opts ...LoadOptionFunc) ([]sdk.Application, error) {
 	query := gorpmapping.NewQuery(`
 	SELECT application.*
 	FROM application
 	WHERE application.id = ANY($1)`).Args(pq.Int64Array(ids))
-	return getAllWithClearVCS(context.Background(), db, opts, query)
+	apps, err := getAllWithClearVCS(context.Background(), db, opts, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range apps {
+		if err := resolveVCSStrategyPassword(context.Background(), &apps[i]); err != nil {
+			return nil, err
+		}
+	}
+	return apps, nil
 }
 
 // LoadAllByIDs returns all applications
@@ -247,16 +957,275 @@ func LoadAllByIDs(db gorp.SqlExecutor, ids []int64, opts ...LoadOptionFunc) ([]s
 	return getAll(context.Background(), db, opts, query)
 }
 
-// LoadAllNames returns all application names
-func LoadAllNames(db gorp.SqlExecutor, projID int64) (sdk.IDNames, error) {
+// PartialLoadResult are the applications loaded by LoadAllByIDsPartial and the requested ids that were not loaded.
+type PartialLoadResult struct {
+	Applications []sdk.Application `json:"applications"`
+	Corrupted    []int64           `json:"corrupted"`
+	NotFound     []int64           `json:"not_found"`
+}
+
+// LoadAllByIDsPartial returns the valid applications for given ids sorted by name, and the requested ids that were not
+// loaded, split between corrupted applications and applications that don't exist. Both id lists are sorted.
+func LoadAllByIDsPartial(ctx context.Context, db gorp.SqlExecutor, ids []int64, opts ...LoadOptionFunc) (*PartialLoadResult, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.id = ANY($1)
+	ORDER BY application.name ASC`).Args(pq.Int64Array(ids))
+	all, err := getAll(ctx, db, opts, query)
+	if err != nil {
+		return nil, err
+	}
+	res := PartialLoadResult{
+		Applications: make([]sdk.Application, 0, len(all)),
+		Corrupted:    []int64{},
+		NotFound:     []int64{},
+	}
+	loaded := make(map[int64]struct{}, len(all))
+	for _, app := range all {
+		res.Applications = append(res.Applications, app)
+		loaded[app.ID] = struct{}{}
+	}
+
+	var existingIDs []int64
+	if _, err := db.Select(&existingIDs, "SELECT id FROM application WHERE id = ANY($1)", pq.Int64Array(ids)); err != nil {
+		return nil, sdk.WrapError(err, "cannot load application ids")
+	}
+	exists := make(map[int64]struct{}, len(existingIDs))
+	for _, id := range existingIDs {
+		exists[id] = struct{}{}
+	}
+
+	seen := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		if _, has := seen[id]; has {
+			continue
+		}
+		seen[id] = struct{}{}
+		if _, has := loaded[id]; has {
+			continue
+		}
+		if _, has := exists[id]; has {
+			res.Corrupted = append(res.Corrupted, id)
+		} else {
+			res.NotFound = append(res.NotFound, id)
+		}
+	}
+	sort.Slice(res.Corrupted, func(i, j int) bool { return res.Corrupted[i] < res.Corrupted[j] })
+	sort.Slice(res.NotFound, func(i, j int) bool { return res.NotFound[i] < res.NotFound[j] })
+	return &res, nil
+}
+
+// LoadAllWithFilter returns all applications of given project matching given filter.
+func LoadAllWithFilter(ctx context.Context, db gorp.SqlExecutor, projectID int64, filter ApplicationFilter, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	if err := filter.IsValid(); err != nil {
+		return nil, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = :projectID
+	AND ` + filter.SQL() + `
+	` + filter.SQLOrderAndPagination()).Args(filter.Args().Merge(gorpmapper.ArgsMap{
+		"projectID": projectID,
+	}))
+	return getAll(ctx, db, opts, query)
+}
+
+// LoadAllWithExpiringKeys returns all applications of given project that hold at least one key
+// expiring before given time. Private content of loaded keys is always masked.
+func LoadAllWithExpiringKeys(ctx context.Context, db gorp.SqlExecutor, projectID int64, before time.Time, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	AND application.id IN (
+		SELECT application_key.application_id
+		FROM application_key
+		WHERE application_key.expire_at IS NOT NULL
+		AND application_key.expire_at < $2
+	)
+	ORDER BY application.name ASC`).Args(projectID, before)
+	apps, err := getAll(ctx, db, opts, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range apps {
+		for j := range apps[i].Keys {
+			apps[i].Keys[j].Private = sdk.PasswordPlaceholder
+		}
+	}
+	return apps, nil
+}
+
+// UpdateLastUsed sets the last time the application was used by a workflow run.
+// The last used date is not part of the signed data so the application is not re-signed.
+func UpdateLastUsed(db gorp.SqlExecutor, appID int64, t time.Time) error {
+	if _, err := db.Exec("UPDATE application SET last_used_at = $2 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)", appID, t); err != nil {
+		return sdk.WrapError(err, "cannot update last used date of application %d", appID)
+	}
+	return nil
+}
+
+// LoadAllUnusedSince returns all applications of given project that were not used by a workflow run since given time
+// including the ones never used.
+func LoadAllUnusedSince(ctx context.Context, db gorp.SqlExecutor, projectID int64, before time.Time, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	AND (application.last_used_at IS NULL OR application.last_used_at < $2)
+	ORDER BY application.name ASC`).Args(projectID, before)
+	return getAll(ctx, db, opts, query)
+}
+
+// LoadAllByKeyType returns all applications of given project that hold at least one key of given type.
+// Private content of loaded keys is always masked.
+func LoadAllByKeyType(ctx context.Context, db gorp.SqlExecutor, projectID int64, keyType string, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	switch sdk.KeyType(keyType) {
+	case sdk.KeyTypeSSH, sdk.KeyTypePGP:
+	default:
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid key type %q", keyType)
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	AND application.id IN (
+		SELECT application_key.application_id
+		FROM application_key
+		WHERE application_key.type = $2
+	)
+	ORDER BY application.name ASC`).Args(projectID, keyType)
+	apps, err := getAll(ctx, db, opts, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range apps {
+		for j := range apps[i].Keys {
+			apps[i].Keys[j].Private = sdk.PasswordPlaceholder
+		}
+	}
+	return apps, nil
+}
+
+// LoadAllByRepositoryPrefix returns all applications of given project which repository fullname starts with given prefix.
+// Wildcards in the prefix are matched literally.
+func LoadAllByRepositoryPrefix(ctx context.Context, db gorp.SqlExecutor, projectID int64, prefix string, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid empty repository prefix")
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	AND application.repo_fullname LIKE $2
+	ORDER BY application.name ASC`).Args(projectID, escapeLikePattern(prefix)+"%")
+	return getAll(ctx, db, opts, query)
+}
+
+var likePatternReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLikePattern escapes given string to be matched literally in a LIKE pattern.
+func escapeLikePattern(s string) string {
+	return likePatternReplacer.Replace(s)
+}
+
+// FindOrphanedByProject returns all applications which project does not exist. Only id, name and project id
+// are loaded because other data can't be unwrapped without a project. Nothing is written.
+func FindOrphanedByProject(ctx context.Context, db gorp.SqlExecutor) ([]sdk.Application, error) {
+	var res []struct {
+		ID        int64  `db:"id"`
+		Name      string `db:"name"`
+		ProjectID int64  `db:"project_id"`
+	}
+	if _, err := db.Select(&res, `
+	SELECT application.id, application.name, application.project_id
+	FROM application
+	LEFT JOIN project ON project.id = application.project_id
+	WHERE project.id IS NULL
+	ORDER BY application.id ASC`); err != nil {
+		return nil, sdk.WrapError(err, "cannot load orphaned applications")
+	}
+	apps := make([]sdk.Application, len(res))
+	for i := range res {
+		apps[i] = sdk.Application{ID: res[i].ID, Name: res[i].Name, ProjectID: res[i].ProjectID}
+	}
+	return apps, nil
+}
+
+// NameAndRepository is a light view of an application used for trigger matching.
+type NameAndRepository struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	FromRepository string `json:"from_repository,omitempty"`
+}
+
+// LoadAllNamesAndRepositoriesByProjectID returns id, name and from_repository of all the applications of a project.
+// Only needed columns are selected and nothing is decrypted, signature is still checked.
+func LoadAllNamesAndRepositoriesByProjectID(ctx context.Context, db gorp.SqlExecutor, projectID int64) ([]NameAndRepository, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.id, application.project_id, application.name, application.from_repository, application.sig
+	FROM application
+	WHERE application.project_id = $1
+	ORDER BY application.name ASC`).Args(projectID)
+
+	var res []dbApplication
+	if err := gorpmapping.GetAll(ctx, db, query, &res); err != nil {
+		return nil, err
+	}
+
+	apps := make([]NameAndRepository, 0, len(res))
+	for i := range res {
+		isValid, err := gorpmapping.CheckSignature(res[i], res[i].Signature)
+		if err != nil {
+			return nil, err
+		}
+		if !isValid {
+			log.Error(ctx, "application.LoadAllNamesAndRepositoriesByProjectID> application %d data corrupted", res[i].ID)
+			alertCorruption(ctx, res[i].ID)
+			continue
+		}
+		apps = append(apps, NameAndRepository{
+			ID:             res[i].ID,
+			Name:           res[i].Name,
+			FromRepository: res[i].FromRepository,
+		})
+	}
+	return apps, nil
+}
+
+// LoadAllNames returns all application names, the query is cancelled when given context is done.
+func LoadAllNames(ctx context.Context, db gorp.SqlExecutor, projID int64) (sdk.IDNames, error) {
+	if err := checkProjectID(projID); err != nil {
+		return nil, err
+	}
 	query := `
-		SELECT application.id, application.name, application.description, application.icon
+		SELECT application.id, application.name, application.description, application.icon, application.color
 		FROM application
 		WHERE application.project_id= $1
 		ORDER BY application.name ASC`
 
 	var res sdk.IDNames
-	if _, err := db.Select(&res, query, projID); err != nil {
+	if _, err := db.WithContext(ctx).Select(&res, query, projID); err != nil {
 		if err == sql.ErrNoRows {
 			return res, nil
 		}
@@ -266,13 +1235,49 @@ func LoadAllNames(db gorp.SqlExecutor, projID int64) (sdk.IDNames, error) {
 	return res, nil
 }
 
+// FindCaseCollisions returns the groups of application names of a project that only differ by case, each group and
+// the groups are ordered by name. It is used to clean existing data before enforcing a case insensitive uniqueness,
+// nothing is written.
+func FindCaseCollisions(ctx context.Context, db gorp.SqlExecutor, projectID int64) ([][]string, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`
+	SELECT array_agg(application.name ORDER BY application.name)
+	FROM application
+	WHERE application.project_id = $1
+	GROUP BY lower(application.name)
+	HAVING COUNT(1) > 1
+	ORDER BY lower(application.name)`, projectID)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot find case collisions for project %d", projectID)
+	}
+	defer rows.Close()
+
+	var res [][]string
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		var names pq.StringArray
+		if err := rows.Scan(&names); err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		res = append(res, names)
+	}
+	return res, sdk.WithStack(rows.Err())
+}
+
 func getAllWithClearVCS(ctx context.Context, db gorp.SqlExecutor, opts []LoadOptionFunc, query gorpmapping.Query) ([]sdk.Application, error) {
 	var res []dbApplication
 	if err := gorpmapping.GetAll(ctx, db, query, &res, gorpmapping.GetOptions.WithDecryption); err != nil {
 		return nil, err
 	}
 
-	apps := make([]sdk.Application, len(res))
+	db = readOnlyLoadDB(ctx, db)
+	loadOpts, batchOpts := splitBatchLoadOptions(opts)
+	serialOpts, concurrentOpts := splitLoadOptions(db, loadOpts)
+	apps := make([]sdk.Application, 0, len(res))
 	for i := range res {
 		isValid, err := gorpmapping.CheckSignature(res[i], res[i].Signature)
 		if err != nil {
@@ -280,14 +1285,25 @@ func getAllWithClearVCS(ctx context.Context, db gorp.SqlExecutor, opts []LoadOpt
 		}
 		if !isValid {
 			log.Error(ctx, "application.getAllWithClearVCS> application %d data corrupted", res[i].ID)
+			alertCorruption(ctx, res[i].ID)
 			continue
 		}
 		a := &res[i]
-		app, err := unwrap(db, opts, a)
+		app, err := unwrap(ctx, db, serialOpts, a)
+		if sdk.Cause(err) == errProjectNotFound {
+			log.Warning(ctx, "application.getAllWithClearVCS> project %d of application %d not found", a.ProjectID, a.ID)
+			continue
+		}
 		if err != nil {
 			return nil, sdk.WrapError(err, "application.getAllWithClearVCS")
 		}
-		apps[i] = *app
+		apps = append(apps, *app)
+	}
+	if err := applyConcurrentLoadOptions(ctx, db, isBestEffort(opts), concurrentOpts, apps); err != nil {
+		return nil, err
+	}
+	if err := applyBatchLoadOptions(ctx, db, isBestEffort(opts), batchOpts, apps); err != nil {
+		return nil, err
 	}
 	return apps, nil
 }
@@ -298,7 +1314,10 @@ func getAll(ctx context.Context, db gorp.SqlExecutor, opts []LoadOptionFunc, que
 		return nil, err
 	}
 
-	apps := make([]sdk.Application, len(res))
+	db = readOnlyLoadDB(ctx, db)
+	loadOpts, batchOpts := splitBatchLoadOptions(opts)
+	serialOpts, concurrentOpts := splitLoadOptions(db, loadOpts)
+	apps := make([]sdk.Application, 0, len(res))
 	for i := range res {
 		isValid, err := gorpmapping.CheckSignature(res[i], res[i].Signature)
 		if err != nil {
@@ -306,24 +1325,59 @@ func getAll(ctx context.Context, db gorp.SqlExecutor, opts []LoadOptionFunc, que
 		}
 		if !isValid {
 			log.Error(ctx, "application.getAll> application %d data corrupted", res[i].ID)
+			alertCorruption(ctx, res[i].ID)
 			continue
 		}
 
 		a := &res[i]
-		app, err := unwrap(db, opts, a)
+		app, err := unwrap(ctx, db, serialOpts, a)
+		if sdk.Cause(err) == errProjectNotFound {
+			log.Warning(ctx, "application.getAll> project %d of application %d not found", a.ProjectID, a.ID)
+			continue
+		}
 		if err != nil {
 			return nil, sdk.WrapError(err, "application.getAll")
 		}
 
 		app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
-		apps[i] = *app
+		applyContextMaskingPolicy(ctx, app)
+		apps = append(apps, *app)
 	}
 
+	if err := applyConcurrentLoadOptions(ctx, db, isBestEffort(opts), concurrentOpts, apps); err != nil {
+		return nil, err
+	}
+	if err := applyBatchLoadOptions(ctx, db, isBestEffort(opts), batchOpts, apps); err != nil {
+		return nil, err
+	}
 	return apps, nil
 }
 
-// LoadIcon return application icon given his application id
-func LoadIcon(db gorp.SqlExecutor, appID int64) (string, error) {
-	icon, err := db.SelectStr("SELECT icon FROM application WHERE id = $1", appID)
+// LoadVCSServer returns the vcs server name of an application, nothing else is read so no secret is decrypted.
+// An empty string is returned if the application has no vcs server.
+func LoadVCSServer(db gorp.SqlExecutor, appID int64) (string, error) {
+	server, err := db.SelectNullStr("SELECT COALESCE(vcs_server, '') FROM application WHERE id = $1", appID)
+	if err != nil {
+		return "", sdk.WrapError(err, "cannot load vcs server of application %d", appID)
+	}
+	if !server.Valid {
+		return "", sdk.WithStack(sdk.ErrNotFound)
+	}
+	return server.String, nil
+}
+
+// LoadIcon return application icon given his application id, the query is cancelled when given context is done.
+func LoadIcon(ctx context.Context, db gorp.SqlExecutor, appID int64) (string, error) {
+	icon, err := db.WithContext(ctx).SelectStr("SELECT icon FROM application WHERE id = $1", appID)
+	return icon, sdk.WithStack(err)
+}
+
+// LoadIconConstrained returns application icon given its application id, or an empty string if the stored icon
+// is larger than maxBytes. Oversized icons are not read from the database.
+func LoadIconConstrained(db gorp.SqlExecutor, appID int64, maxBytes int) (string, error) {
+	if maxBytes <= 0 {
+		return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid max icon size %d", maxBytes)
+	}
+	icon, err := db.SelectStr("SELECT CASE WHEN octet_length(icon) > $2 THEN '' ELSE icon END FROM application WHERE id = $1", appID, maxBytes)
 	return icon, sdk.WithStack(err)
 }
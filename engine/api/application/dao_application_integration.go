@@ -155,7 +155,10 @@ func SetDeploymentStrategy(db gorpmapper.SqlExecutorWithTx, projID, appID, pfMod
 	if err != nil {
 		return err
 	}
+	return setDeploymentStrategy(db, projectIntegrationID, appID, cfg)
+}
 
+func setDeploymentStrategy(db gorpmapper.SqlExecutorWithTx, projectIntegrationID, appID int64, cfg sdk.IntegrationConfig) error {
 	dbCfg, err := findDeploymentStrategy(db, projectIntegrationID, appID)
 	if err != nil {
 		return err
@@ -25,8 +25,8 @@ func Test_LoadAllDeploymentAllApps(t *testing.T) {
 	app2 := sdk.Application{
 		Name: "my-app2",
 	}
-	require.NoError(t, application.Insert(db, *proj, &app1))
-	require.NoError(t, application.Insert(db, *proj, &app2))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
 
 	pfname := sdk.RandomString(10)
 	pf := sdk.IntegrationModel{
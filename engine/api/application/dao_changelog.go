@@ -0,0 +1,201 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// applicationChangeData is the application state stored in the changelog.
+type applicationChangeData sdk.Application
+
+// Value returns driver.Value from applicationChangeData.
+func (d applicationChangeData) Value() (driver.Value, error) {
+	j, err := json.Marshal(d)
+	return j, sdk.WrapError(err, "cannot marshal applicationChangeData")
+}
+
+// Scan applicationChangeData.
+func (d *applicationChangeData) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return sdk.WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return sdk.WrapError(json.Unmarshal(source, d), "cannot unmarshal applicationChangeData")
+}
+
+type dbApplicationChange struct {
+	sdk.ApplicationChange
+	Data *applicationChangeData `db:"data"`
+}
+
+// newApplicationChangeData returns a copy of the application without secrets and aggregates.
+func newApplicationChangeData(app sdk.Application) *applicationChangeData {
+	app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
+	app.RepositoryStrategy.SSHKeyContent = ""
+	app.Variables = nil
+	app.Keys = nil
+	app.DeploymentStrategies = nil
+	app.Vulnerabilities = nil
+	app.Notifications = nil
+	app.Usage = nil
+	app.WorkflowAscodeHolder = nil
+	d := applicationChangeData(app)
+	return &d
+}
+
+// insertChange records a change in the applications changelog, it should be called in the same transaction as the change.
+// The change date is the database start time of the transaction, so all changes of a transaction share the same date.
+func insertChange(db gorp.SqlExecutor, changeType string, appID, projectID int64, app *sdk.Application) error {
+	var xid int64
+	var created time.Time
+	if err := db.QueryRow("SELECT txid_current(), now()").Scan(&xid, &created); err != nil {
+		return sdk.WrapError(err, "cannot get current transaction id")
+	}
+	c := dbApplicationChange{
+		ApplicationChange: sdk.ApplicationChange{
+			Xid:           xid,
+			ApplicationID: appID,
+			ProjectID:     projectID,
+			Type:          changeType,
+			Created:       created,
+		},
+	}
+	if app != nil {
+		c.Data = newApplicationChangeData(*app)
+	}
+	if err := db.Insert(&c); err != nil {
+		return sdk.WrapError(err, "cannot insert %s change for application %d", changeType, appID)
+	}
+	return nil
+}
+
+// insertUpdateChanges records an update change with the stored state of each given application, it is called by the
+// writers that don't load the whole application, like the protected column setters. Writes of the last accessed and
+// last used dates and of the content hash are not recorded.
+func insertUpdateChanges(ctx context.Context, db gorp.SqlExecutor, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := gorpmapping.NewQuery("SELECT application.* FROM application WHERE application.id = ANY($1)").Args(pq.Int64Array(ids))
+	apps, err := getAll(ctx, db, nil, query)
+	if err != nil {
+		return err
+	}
+	for i := range apps {
+		if err := insertChange(db, sdk.ApplicationChangeUpdate, apps[i].ID, apps[i].ProjectID, &apps[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeChanges deletes the changes written before given time and returns the number of deleted changes.
+func PurgeChanges(db gorp.SqlExecutor, before time.Time) (int64, error) {
+	res, err := db.Exec("DELETE FROM application_changelog WHERE created < $1", before)
+	if err != nil {
+		return 0, sdk.WrapError(err, "cannot purge application changes")
+	}
+	n, err := res.RowsAffected()
+	return n, sdk.WithStack(err)
+}
+
+// PurgeChangelog deletes the changes older than given retention each purge interval, until given context is done.
+func PurgeChangelog(ctx context.Context, db gorp.SqlExecutor, retention, purgeInterval time.Duration) {
+	tick := time.NewTicker(purgeInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if _, err := PurgeChanges(db, time.Now().Add(-retention)); err != nil {
+				log.Error(ctx, "application.PurgeChangelog> %v", err)
+			}
+		}
+	}
+}
+
+// ReadChanges returns at most limit changes after given cursor, ordered by transaction id then by sequence.
+// A consumer should store the cursor of the last change it read and use it for next call.
+// Sequences are allocated before commit, so only the changes written by transactions older than every
+// in-flight transaction are returned: a change that commits late can't be skipped, but a long transaction
+// delays the changes written after it started.
+func ReadChanges(ctx context.Context, db gorp.SqlExecutor, after sdk.ApplicationChangeCursor, limit int) ([]sdk.ApplicationChange, error) {
+	if limit <= 0 {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid limit %d", limit)
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT *
+	FROM application_changelog
+	WHERE (xid, seq) > ($1, $2)
+	AND xid < txid_snapshot_xmin(txid_current_snapshot())
+	ORDER BY xid ASC, seq ASC
+	LIMIT $3`).Args(after.Xid, after.Seq, limit)
+	return getChanges(ctx, db, query)
+}
+
+// LastChangeCursor returns the cursor of the last change that can be read, it is used to start reading
+// the changelog from now.
+func LastChangeCursor(ctx context.Context, db gorp.SqlExecutor) (sdk.ApplicationChangeCursor, error) {
+	var c sdk.ApplicationChangeCursor
+	if err := db.WithContext(ctx).SelectOne(&c, `
+	SELECT xid, seq
+	FROM application_changelog
+	WHERE xid < txid_snapshot_xmin(txid_current_snapshot())
+	ORDER BY xid DESC, seq DESC
+	LIMIT 1`); err != nil && err != sql.ErrNoRows {
+		return c, sdk.WrapError(err, "cannot load last change cursor")
+	}
+	return c, nil
+}
+
+func getChanges(ctx context.Context, db gorp.SqlExecutor, query gorpmapping.Query) ([]sdk.ApplicationChange, error) {
+	var res []dbApplicationChange
+	if err := gorpmapping.GetAll(ctx, db, query, &res); err != nil {
+		return nil, err
+	}
+	changes := make([]sdk.ApplicationChange, len(res))
+	for i := range res {
+		changes[i] = res[i].ApplicationChange
+		if res[i].Data != nil {
+			app := sdk.Application(*res[i].Data)
+			changes[i].Application = &app
+		}
+	}
+	return changes, nil
+}
+
+// LoadByIDAtTime returns the application as it was at given time from the changelog, from its last change in
+// commit order written by a transaction started before given time. Secrets are masked and variables, keys and
+// deployment strategies are not available in historical views.
+func LoadByIDAtTime(ctx context.Context, db gorp.SqlExecutor, appID int64, t time.Time) (*sdk.Application, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT *
+	FROM application_changelog
+	WHERE application_id = $1
+	AND created <= $2
+	ORDER BY xid DESC, seq DESC
+	LIMIT 1`).Args(appID, t)
+	changes, err := getChanges(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 || changes[0].Type == sdk.ApplicationChangeDelete || changes[0].Application == nil {
+		return nil, sdk.NewErrorFrom(sdk.ErrNotFound, "application %d did not exist at %v", appID, t)
+	}
+	return changes[0].Application, nil
+}
@@ -0,0 +1,179 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestReadChanges(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	last, err := application.LastChangeCursor(context.TODO(), db)
+	require.NoError(t, err)
+
+	app := &sdk.Application{
+		Name: "my-app",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "user",
+			Password:       "vcs_secret",
+		},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+	app.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, app))
+	require.NoError(t, application.DeleteApplication(context.TODO(), db, app.ID))
+
+	changes, err := application.ReadChanges(context.TODO(), db, last, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	require.Equal(t, sdk.ApplicationChangeInsert, changes[0].Type)
+	require.Equal(t, app.ID, changes[0].ApplicationID)
+	require.Equal(t, proj.ID, changes[0].ProjectID)
+	require.NotNil(t, changes[0].Application)
+	require.Equal(t, sdk.PasswordPlaceholder, changes[0].Application.RepositoryStrategy.Password)
+
+	require.Equal(t, sdk.ApplicationChangeUpdate, changes[1].Type)
+	require.Equal(t, "my description", changes[1].Application.Description)
+	require.True(t, changes[1].Xid > changes[0].Xid)
+
+	require.Equal(t, sdk.ApplicationChangeDelete, changes[2].Type)
+	require.Nil(t, changes[2].Application)
+	require.True(t, changes[2].Xid > changes[1].Xid)
+
+	changes, err = application.ReadChanges(context.TODO(), db, changes[0].Cursor(), 1)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, sdk.ApplicationChangeUpdate, changes[0].Type)
+
+	_, err = application.ReadChanges(context.TODO(), db, last, 0)
+	require.Error(t, err)
+
+	// Changes of a transaction in progress are not readable, even once a later transaction is committed
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback() // nolint
+	last = changes[0].Cursor()
+	app2 := &sdk.Application{Name: "my-app-2"}
+	require.NoError(t, application.Insert(context.TODO(), tx, *proj, app2))
+	app3 := &sdk.Application{Name: "my-app-3"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app3))
+
+	changes, err = application.ReadChanges(context.TODO(), db, last, 10)
+	require.NoError(t, err)
+	for _, c := range changes {
+		require.NotEqual(t, app3.ID, c.ApplicationID)
+	}
+
+	require.NoError(t, tx.Commit())
+	changes, err = application.ReadChanges(context.TODO(), db, last, 10)
+	require.NoError(t, err)
+	var ids []int64
+	for _, c := range changes {
+		ids = append(ids, c.ApplicationID)
+	}
+	require.Contains(t, ids, app2.ID)
+	require.Contains(t, ids, app3.ID)
+}
+
+func TestReadChangesPartialWriters(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app := &sdk.Application{Name: "my-app", FromRepository: "https://github.com/ovh/cds"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+	last, err := application.LastChangeCursor(context.TODO(), db)
+	require.NoError(t, err)
+
+	require.NoError(t, application.SetColor(db, app.ID, "blue"))
+	require.NoError(t, application.SetDeployAllowlist(db, app.ID, sdk.ApplicationDeployAllowlist{Environments: []string{"prod"}}))
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback() // nolint
+	n, err := application.DetachFromRepository(context.TODO(), tx, proj.ID, []int64{app.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.NoError(t, tx.Commit())
+
+	changes, err := application.ReadChanges(context.TODO(), db, last, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	for _, c := range changes {
+		require.Equal(t, sdk.ApplicationChangeUpdate, c.Type)
+		require.Equal(t, app.ID, c.ApplicationID)
+	}
+	require.Equal(t, "blue", changes[0].Application.Color)
+	require.Equal(t, "https://github.com/ovh/cds", changes[1].Application.FromRepository)
+	require.Equal(t, "", changes[2].Application.FromRepository)
+}
+
+func TestPurgeChanges(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app := &sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+	last, err := application.LastChangeCursor(context.TODO(), db)
+	require.NoError(t, err)
+	require.NoError(t, application.SetColor(db, app.ID, "blue"))
+
+	_, err = application.PurgeChanges(db, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	changes, err := application.ReadChanges(context.TODO(), db, last, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	n, err := application.PurgeChanges(db, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, n >= 2)
+	changes, err = application.ReadChanges(context.TODO(), db, last, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 0)
+}
+
+func TestLoadByIDAtTime(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	beforeInsert := time.Now()
+	app := &sdk.Application{Name: "my-app", Description: "first"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+	afterInsert := time.Now()
+	app.Description = "second"
+	require.NoError(t, application.Update(context.TODO(), db, app))
+	afterUpdate := time.Now()
+	require.NoError(t, application.DeleteApplication(context.TODO(), db, app.ID))
+
+	_, err := application.LoadByIDAtTime(context.TODO(), db, app.ID, beforeInsert)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+
+	old, err := application.LoadByIDAtTime(context.TODO(), db, app.ID, afterInsert)
+	require.NoError(t, err)
+	require.Equal(t, "first", old.Description)
+
+	old, err = application.LoadByIDAtTime(context.TODO(), db, app.ID, afterUpdate)
+	require.NoError(t, err)
+	require.Equal(t, "second", old.Description)
+
+	_, err = application.LoadByIDAtTime(context.TODO(), db, app.ID, time.Now())
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
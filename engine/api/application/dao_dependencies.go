@@ -1,6 +1,7 @@
 package application
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/go-gorp/gorp"
@@ -9,6 +10,32 @@ import (
 )
 
 var (
+	// loadBestEffort does nothing, it marks the load so errors from other options are logged instead of returned.
+	loadBestEffort = func(db gorp.SqlExecutor, app *sdk.Application) error {
+		return nil
+	}
+
+	// loadExistingProject does nothing, it marks the load so applications which project does not exist are skipped
+	// by list loaders and not found by single loaders.
+	loadExistingProject = func(db gorp.SqlExecutor, app *sdk.Application) error {
+		return nil
+	}
+
+	// loadAccessTracking loads nothing, it queues the application to be marked as accessed if TrackAccess is running.
+	loadAccessTracking = func(db gorp.SqlExecutor, app *sdk.Application) error {
+		trackAccess(app.ID)
+		return nil
+	}
+
+	loadPipelines = func(db gorp.SqlExecutor, app *sdk.Application) error {
+		pips, err := LoadPipelinesByApplicationIDs(db, []int64{app.ID})
+		if err != nil {
+			return err
+		}
+		app.Pipelines = pips[app.ID]
+		return nil
+	}
+
 	loadDefaultDependencies = func(db gorp.SqlExecutor, app *sdk.Application) error {
 		if err := loadVariables(db, app); err != nil && sdk.Cause(err) != sql.ErrNoRows {
 			return sdk.WrapError(err, "application.loadDefaultDependencies %s", app.Name)
@@ -34,6 +61,24 @@ var (
 		return nil
 	}
 
+	loadVariablesWithClearPasswordSkipUndecryptable = func(db gorp.SqlExecutor, app *sdk.Application) error {
+		variables, err := LoadAllVariablesWithDecryptionSkipUndecryptable(db, app.ID)
+		if err != nil && sdk.Cause(err) != sql.ErrNoRows {
+			return sdk.WrapError(err, "Unable to load variables for application %d", app.ID)
+		}
+		app.Variables = variables
+		return nil
+	}
+
+	loadWebhooks = func(db gorp.SqlExecutor, app *sdk.Application) error {
+		webhooks, err := LoadWebhooks(db, app.ID, false)
+		if err != nil {
+			return err
+		}
+		app.Webhooks = webhooks
+		return nil
+	}
+
 	loadKeys = func(db gorp.SqlExecutor, app *sdk.Application) error {
 		keys, err := LoadAllKeys(db, app.ID)
 		if err != nil {
@@ -63,7 +108,7 @@ var (
 
 	loadIcon = func(db gorp.SqlExecutor, app *sdk.Application) error {
 		var err error
-		app.Icon, err = LoadIcon(db, app.ID)
+		app.Icon, err = LoadIcon(context.Background(), db, app.ID)
 		if err != nil && sdk.Cause(err) != sql.ErrNoRows {
 			return sdk.WrapError(err, "Unable to load icon")
 		}
@@ -0,0 +1,54 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// LoadEnvironmentsByApplicationIDs returns for each given application the environments it is used with in workflow nodes.
+// Only environment fields are loaded, not their variables and keys.
+func LoadEnvironmentsByApplicationIDs(db gorp.SqlExecutor, appIDs []int64) (map[int64][]sdk.Environment, error) {
+	query := `
+	SELECT DISTINCT w_node_context.application_id, environment.id, environment.name, environment.project_id,
+		environment.created, environment.last_modified, environment.from_repository
+	FROM environment
+	JOIN w_node_context ON w_node_context.environment_id = environment.id
+	WHERE w_node_context.application_id = ANY($1)
+	ORDER BY environment.name`
+	rows, err := db.Query(query, pq.Int64Array(appIDs))
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load environments linked to applications %v", appIDs)
+	}
+	defer rows.Close()
+
+	res := make(map[int64][]sdk.Environment, len(appIDs))
+	for rows.Next() {
+		var appID int64
+		var env sdk.Environment
+		if err := rows.Scan(&appID, &env.ID, &env.Name, &env.ProjectID,
+			&env.Created, &env.LastModified, &env.FromRepository); err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		res[appID] = append(res[appID], env)
+	}
+	return res, sdk.WithStack(rows.Err())
+}
+
+// LoadAllByEnvironmentID returns all applications used with given environment in workflow nodes.
+func LoadAllByEnvironmentID(ctx context.Context, db gorp.SqlExecutor, envID int64, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.id IN (
+		SELECT w_node_context.application_id
+		FROM w_node_context
+		WHERE w_node_context.environment_id = $1
+	)
+	ORDER BY application.name ASC`).Args(envID)
+	return getAll(ctx, db, opts, query)
+}
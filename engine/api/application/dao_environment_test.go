@@ -0,0 +1,64 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/environment"
+	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadEnvironmentsByApplicationIDs(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := sdk.Application{Name: "my-app1"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	app2 := sdk.Application{Name: "my-app2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+	env := sdk.Environment{Name: "my-env", ProjectID: proj.ID, ProjectKey: proj.Key}
+	require.NoError(t, environment.InsertEnvironment(db, &env))
+	pip := sdk.Pipeline{ProjectID: proj.ID, ProjectKey: proj.Key, Name: "pip1"}
+	require.NoError(t, pipeline.InsertPipeline(db, &pip))
+
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		WorkflowData: sdk.WorkflowData{
+			Node: sdk.Node{
+				Type: sdk.NodeTypePipeline,
+				Context: &sdk.NodeContext{
+					PipelineID:    pip.ID,
+					ApplicationID: app1.ID,
+					EnvironmentID: env.ID,
+				},
+			},
+		},
+	}
+	require.NoError(t, workflow.RenameNode(context.TODO(), db, &w))
+	proj, _ = project.LoadByID(db, proj.ID, project.LoadOptions.WithApplications, project.LoadOptions.WithPipelines, project.LoadOptions.WithEnvironments, project.LoadOptions.WithGroups)
+	require.NoError(t, workflow.Insert(context.TODO(), db, cache, *proj, &w))
+
+	envs, err := application.LoadEnvironmentsByApplicationIDs(db, []int64{app1.ID, app2.ID})
+	require.NoError(t, err)
+	require.Len(t, envs, 1)
+	require.Len(t, envs[app1.ID], 1)
+	require.Equal(t, env.ID, envs[app1.ID][0].ID)
+	require.Equal(t, "my-env", envs[app1.ID][0].Name)
+
+	apps, err := application.LoadAllByEnvironmentID(context.TODO(), db, env.ID)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, app1.ID, apps[0].ID)
+}
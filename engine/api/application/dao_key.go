@@ -24,10 +24,13 @@ func (e dbApplicationKey) Canonical() gorpmapper.CanonicalForms {
 	}
 }
 
-// InsertKey a new application key in database
-func InsertKey(db gorpmapper.SqlExecutorWithTx, key *sdk.ApplicationKey) error {
+// InsertKey a new application key in database, sdk.ErrForbidden is returned if the application is read only.
+func InsertKey(ctx context.Context, db gorpmapper.SqlExecutorWithTx, key *sdk.ApplicationKey) error {
+	if err := CheckWritable(ctx, db, key.ApplicationID); err != nil {
+		return err
+	}
 	var dbAppKey = dbApplicationKey{ApplicationKey: *key}
-	if err := gorpmapping.InsertAndSign(context.Background(), db, &dbAppKey); err != nil {
+	if err := gorpmapping.InsertAndSign(ctx, db, &dbAppKey); err != nil {
 		return err
 	}
 	*key = dbAppKey.ApplicationKey
@@ -120,6 +123,32 @@ func loadKey(db gorp.SqlExecutor, id int64, keyName string) (*sdk.ApplicationKey
 	return &k.ApplicationKey, nil
 }
 
+// LoadKey returns the private content of the named key of an application, only this key is decrypted.
+// It returns sdk.ErrNotFound if the application has no key with given name.
+func LoadKey(ctx context.Context, db gorp.SqlExecutor, appID int64, keyName string) ([]byte, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT *
+	FROM application_key
+	WHERE application_id = $1 AND name = $2`).Args(appID, keyName)
+	var k dbApplicationKey
+	found, err := gorpmapping.Get(ctx, db, query, &k, gorpmapping.GetOptions.WithDecryption)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, sdk.NewErrorFrom(sdk.ErrNotFound, "key %s not found for application %d", keyName, appID)
+	}
+	isValid, err := gorpmapping.CheckSignature(k, k.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		log.Error(ctx, "application.LoadKey> application key %d data corrupted", k.ID)
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+	return []byte(k.Private), nil
+}
+
 // LoadAllKeysForAppsWithDecryption load all keys for all given applications, with decryption
 func LoadAllKeysForAppsWithDecryption(ctx context.Context, db gorp.SqlExecutor, appIDs []int64) (map[int64][]sdk.ApplicationKey, error) {
 	return loadAllKeysForApps(ctx, db, appIDs, gorpmapping.GetOptions.WithDecryption)
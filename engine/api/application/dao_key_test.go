@@ -3,6 +3,7 @@ package application_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/keys"
@@ -22,7 +23,7 @@ func Test_DAOKey(t *testing.T) {
 		Name: "my-app",
 	}
 
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	k := &sdk.ApplicationKey{
 		Name:          "mykey-ssh",
@@ -35,7 +36,7 @@ func Test_DAOKey(t *testing.T) {
 	k.Public = kssh.Public
 	k.Private = kssh.Private
 	k.KeyID = kssh.KeyID
-	require.NoError(t, application.InsertKey(db, k))
+	require.NoError(t, application.InsertKey(context.TODO(), db, k))
 	assert.Equal(t, sdk.PasswordPlaceholder, k.Private)
 
 	ks, err := application.LoadAllKeys(db, app.ID)
@@ -62,8 +63,8 @@ func Test_DAOAllKeysAllApps(t *testing.T) {
 	app2 := sdk.Application{
 		Name: "my-app2",
 	}
-	require.NoError(t, application.Insert(db, *proj, &app1))
-	require.NoError(t, application.Insert(db, *proj, &app2))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
 
 	ssh1, err := keys.GenerateSSHKey("ssh1")
 	require.NoError(t, err)
@@ -71,8 +72,8 @@ func Test_DAOAllKeysAllApps(t *testing.T) {
 	require.NoError(t, err)
 	appssh1 := sdk.ApplicationKey{ApplicationID: app1.ID, Type: sdk.KeyTypeSSH, Name: "ssh1", Public: ssh1.Public, Private: ssh1.Private}
 	appssh2 := sdk.ApplicationKey{ApplicationID: app2.ID, Type: sdk.KeyTypeSSH, Name: "ssh2", Public: ssh2.Public, Private: ssh2.Private}
-	require.NoError(t, application.InsertKey(db, &appssh1))
-	require.NoError(t, application.InsertKey(db, &appssh2))
+	require.NoError(t, application.InsertKey(context.TODO(), db, &appssh1))
+	require.NoError(t, application.InsertKey(context.TODO(), db, &appssh2))
 
 	keys, err := application.LoadAllKeysForAppsWithDecryption(context.TODO(), db, []int64{app1.ID, app2.ID})
 	require.NoError(t, err)
@@ -87,3 +88,95 @@ func Test_DAOAllKeysAllApps(t *testing.T) {
 	require.Contains(t, keys[app2.ID][0].Private, "PRIVATE")
 	require.Contains(t, keys[app2.ID][0].Name, "ssh2")
 }
+
+func Test_LoadAllWithExpiringKeys(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := sdk.Application{Name: "my-app1"}
+	app2 := sdk.Application{Name: "my-app2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().Add(365 * 24 * time.Hour)
+
+	ssh1, err := keys.GenerateSSHKey("ssh1")
+	require.NoError(t, err)
+	ssh2, err := keys.GenerateSSHKey("ssh2")
+	require.NoError(t, err)
+	appssh1 := sdk.ApplicationKey{ApplicationID: app1.ID, Type: sdk.KeyTypeSSH, Name: "ssh1", Public: ssh1.Public, Private: ssh1.Private, ExpireAt: &soon}
+	appssh2 := sdk.ApplicationKey{ApplicationID: app2.ID, Type: sdk.KeyTypeSSH, Name: "ssh2", Public: ssh2.Public, Private: ssh2.Private, ExpireAt: &later}
+	require.NoError(t, application.InsertKey(context.TODO(), db, &appssh1))
+	require.NoError(t, application.InsertKey(context.TODO(), db, &appssh2))
+
+	apps, err := application.LoadAllWithExpiringKeys(context.TODO(), db, proj.ID, time.Now().Add(7*24*time.Hour), application.LoadOptions.WithClearKeys)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, app1.ID, apps[0].ID)
+	require.Len(t, apps[0].Keys, 1)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].Keys[0].Private)
+}
+
+func Test_LoadAllByKeyType(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := sdk.Application{Name: "my-app1"}
+	app2 := sdk.Application{Name: "my-app2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	ssh, err := keys.GenerateSSHKey("ssh")
+	require.NoError(t, err)
+	pgp, err := keys.GeneratePGPKeyPair("pgp")
+	require.NoError(t, err)
+	appssh := sdk.ApplicationKey{ApplicationID: app1.ID, Type: sdk.KeyTypeSSH, Name: "ssh", Public: ssh.Public, Private: ssh.Private}
+	apppgp := sdk.ApplicationKey{ApplicationID: app2.ID, Type: sdk.KeyTypePGP, Name: "pgp", Public: pgp.Public, Private: pgp.Private, KeyID: pgp.KeyID}
+	require.NoError(t, application.InsertKey(context.TODO(), db, &appssh))
+	require.NoError(t, application.InsertKey(context.TODO(), db, &apppgp))
+
+	apps, err := application.LoadAllByKeyType(context.TODO(), db, proj.ID, string(sdk.KeyTypeSSH), application.LoadOptions.WithClearKeys)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, app1.ID, apps[0].ID)
+	require.Len(t, apps[0].Keys, 1)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].Keys[0].Private)
+
+	apps, err = application.LoadAllByKeyType(context.TODO(), db, proj.ID, string(sdk.KeyTypePGP))
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, app2.ID, apps[0].ID)
+
+	_, err = application.LoadAllByKeyType(context.TODO(), db, proj.ID, "unknown")
+	require.Error(t, err)
+}
+
+func Test_LoadKey(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	kssh, err := keys.GenerateSSHKey("mykey-ssh")
+	require.NoError(t, err)
+	require.NoError(t, application.InsertKey(context.TODO(), db, &sdk.ApplicationKey{
+		Name:          "mykey-ssh",
+		Type:          sdk.KeyTypeSSH,
+		ApplicationID: app.ID,
+		Public:        kssh.Public,
+		Private:       kssh.Private,
+		KeyID:         kssh.KeyID,
+	}))
+
+	private, err := application.LoadKey(context.TODO(), db, app.ID, "mykey-ssh")
+	require.NoError(t, err)
+	assert.Equal(t, kssh.Private, string(private))
+
+	_, err = application.LoadKey(context.TODO(), db, app.ID, "unknown")
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
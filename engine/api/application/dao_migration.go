@@ -0,0 +1,160 @@
+package application
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+// updateColumnsAllowed are the application columns that can be updated with UpdateColumns. Identity columns and
+// signature are excluded, flags have their own setters.
+var updateColumnsAllowed = map[string]struct{}{
+	"description":         {},
+	"icon":                {},
+	"vcs_server":          {},
+	"repo_fullname":       {},
+	"cipher_vcs_strategy": {},
+	"metadata":            {},
+	"from_repository":     {},
+	"last_modified":       {},
+}
+
+// ContextWithMigration returns a copy of the context that allows to call UpdateColumns and UpdateColumnsMany.
+// It should only be used by migrations.
+func ContextWithMigration(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextMigration, true)
+}
+
+func checkMigration(ctx context.Context) error {
+	if isMigration, _ := ctx.Value(contextMigration).(bool); !isMigration {
+		return sdk.NewErrorFrom(sdk.ErrForbidden, "columns can only be updated by a migration")
+	}
+	return nil
+}
+
+// checkUpdateColumnsFilter returns an error if given filter selects a column that is not allowed.
+func checkUpdateColumnsFilter(columnFilter gorp.ColumnFilter) error {
+	if columnFilter == nil {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "missing column filter")
+	}
+	columns := []string{"sig", "signer"}
+	t := reflect.TypeOf(sdk.Application{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get("db"); name != "" && name != "-" {
+			columns = append(columns, name)
+		}
+	}
+	var selected bool
+	for _, name := range columns {
+		if !columnFilter(&gorp.ColumnMap{ColumnName: name}) {
+			continue
+		}
+		if _, ok := updateColumnsAllowed[name]; !ok {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "column %s can't be updated with UpdateColumns", name)
+		}
+		selected = true
+	}
+	if !selected {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "column filter doesn't select any column")
+	}
+	return nil
+}
+
+// checkNotRenamed returns an error if one of given applications was renamed or moved since it was loaded, because
+// their signature is computed from the loaded data.
+func checkNotRenamed(db gorp.SqlExecutor, apps []*sdk.Application) error {
+	ids := make([]int64, len(apps))
+	for i := range apps {
+		ids[i] = apps[i].ID
+	}
+	var currents []struct {
+		ID        int64  `db:"id"`
+		ProjectID int64  `db:"project_id"`
+		Name      string `db:"name"`
+	}
+	if _, err := db.Select(&currents, "SELECT id, project_id, name FROM application WHERE id = ANY($1)", pq.Int64Array(ids)); err != nil {
+		return sdk.WrapError(err, "cannot load applications %v", ids)
+	}
+	byID := make(map[int64]int, len(currents))
+	for i := range currents {
+		byID[currents[i].ID] = i
+	}
+	for _, app := range apps {
+		i, ok := byID[app.ID]
+		if !ok {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "application %d not found", app.ID)
+		}
+		if currents[i].ProjectID != app.ProjectID || currents[i].Name != app.Name {
+			return sdk.NewErrorFrom(sdk.ErrConflictData, "application %d was renamed or moved since it was loaded", app.ID)
+		}
+	}
+	return nil
+}
+
+// UpdateColumns update given columns of an application, re-sign it, refresh its content hash and record the change.
+// Only the columns in updateColumnsAllowed can be selected by the filter, sdk.ErrWrongRequest is returned otherwise.
+// This function should be use only for migration purpose and should be removed, sdk.ErrForbidden is returned if given
+// context is not marked with ContextWithMigration.
+func UpdateColumns(ctx context.Context, db gorpmapper.SqlExecutorWithTx, app *sdk.Application, columnFilter gorp.ColumnFilter) error {
+	return UpdateColumnsMany(ctx, db, []*sdk.Application{app}, columnFilter, nil)
+}
+
+// UpdateColumnsProgressFunc is called by UpdateColumnsMany after each updated application.
+type UpdateColumnsProgressFunc func(done, total int)
+
+// UpdateColumnsMany update given columns of all given applications, re-sign them, refresh their content hash and
+// record an update change for each of them in the changelog.
+// All updates are done in the given transaction, so nothing is written if one of them fails.
+// Locks, rename checks and content hashes are done with one query for all applications, but each application is
+// still written with its own statement because its signature and encrypted columns are computed by the mapper.
+// This function should be use only for migration purpose and should be removed, sdk.ErrForbidden is returned if given
+// context is not marked with ContextWithMigration.
+func UpdateColumnsMany(ctx context.Context, db gorpmapper.SqlExecutorWithTx, apps []*sdk.Application, columnFilter gorp.ColumnFilter, progress UpdateColumnsProgressFunc) error {
+	if err := checkMigration(ctx); err != nil {
+		return err
+	}
+	if err := checkUpdateColumnsFilter(columnFilter); err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(apps))
+	for i := range apps {
+		ids[i] = apps[i].ID
+	}
+	if err := LockApplications(ctx, db, ids); err != nil {
+		return err
+	}
+	if err := checkNotRenamed(db, apps); err != nil {
+		return err
+	}
+	vcsStrategyUpdated := columnFilter(&gorp.ColumnMap{ColumnName: "cipher_vcs_strategy"})
+	for i := range apps {
+		if err := ctx.Err(); err != nil {
+			return sdk.WithStack(err)
+		}
+		dbApp := dbApplication{Application: *apps[i]}
+		if err := gorpmapping.UpdateColumnsAndSign(ctx, db, &dbApp, columnFilter); err != nil {
+			return sdk.WrapError(err, "application.UpdateColumns %s(%d)", apps[i].Name, apps[i].ID)
+		}
+		if vcsStrategyUpdated {
+			if err := setVCSStrategyColumns(db, apps[i].ID, apps[i].RepositoryStrategy); err != nil {
+				return err
+			}
+		}
+		if progress != nil {
+			progress(i+1, len(apps))
+		}
+	}
+	if err := setContentHashes(ctx, db, ids); err != nil {
+		return err
+	}
+	return insertUpdateChanges(ctx, db, ids)
+}
@@ -0,0 +1,82 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_UpdateColumnsMany(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	var apps []*sdk.Application
+	etags := make(map[int64]string)
+	for _, name := range []string{"my-app1", "my-app2", "my-app3"} {
+		app := &sdk.Application{Name: name}
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+		_, _, etag, err := application.LoadMeta(db, proj.ID, name)
+		require.NoError(t, err)
+		etags[app.ID] = etag
+		app.Description = "migrated"
+		apps = append(apps, app)
+	}
+
+	// Columns can only be updated by a migration
+	err := application.UpdateColumnsMany(context.TODO(), db, apps, func(col *gorp.ColumnMap) bool {
+		return col.ColumnName == "description"
+	}, nil)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrForbidden))
+
+	var progress []int
+	require.NoError(t, application.UpdateColumnsMany(application.ContextWithMigration(context.TODO()), db, apps, func(col *gorp.ColumnMap) bool {
+		return col.ColumnName == "description"
+	}, func(done, total int) {
+		require.Equal(t, len(apps), total)
+		progress = append(progress, done)
+	}))
+	require.Equal(t, []int{1, 2, 3}, progress)
+
+	for _, app := range apps {
+		res, err := application.LoadByID(db, app.ID)
+		require.NoError(t, err)
+		require.Equal(t, "migrated", res.Description)
+		_, _, etag, err := application.LoadMeta(db, proj.ID, app.Name)
+		require.NoError(t, err)
+		require.NotEqual(t, etags[app.ID], etag)
+	}
+}
+
+func Test_UpdateColumnsRejectedColumns(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := &sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+
+	for _, column := range []string{"sig", "signer", "project_id", "name", "id", "read_only"} {
+		app.Name = "renamed"
+		err := application.UpdateColumns(application.ContextWithMigration(context.TODO()), db, app, func(col *gorp.ColumnMap) bool {
+			return col.ColumnName == "description" || col.ColumnName == column
+		})
+		require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest), "column %s should be rejected", column)
+	}
+
+	err := application.UpdateColumns(application.ContextWithMigration(context.TODO()), db, app, func(col *gorp.ColumnMap) bool { return false })
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+	require.True(t, sdk.ErrorIs(application.UpdateColumns(application.ContextWithMigration(context.TODO()), db, app, nil), sdk.ErrWrongRequest))
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-app", res.Name)
+}
@@ -0,0 +1,51 @@
+package application
+
+import (
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// LoadPipelinesByApplicationIDs returns for each given application the pipelines it is used with in workflow nodes.
+// Only pipeline fields are loaded, not their stages and parameters.
+func LoadPipelinesByApplicationIDs(db gorp.SqlExecutor, appIDs []int64) (map[int64][]sdk.Pipeline, error) {
+	query := `
+	SELECT DISTINCT w_node_context.application_id, pipeline.id, pipeline.name, COALESCE(pipeline.description, ''),
+		pipeline.project_id, COALESCE(pipeline.from_repository, '')
+	FROM pipeline
+	JOIN w_node_context ON w_node_context.pipeline_id = pipeline.id
+	WHERE w_node_context.application_id = ANY($1)
+	ORDER BY pipeline.name`
+	rows, err := db.Query(query, pq.Int64Array(appIDs))
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load pipelines linked to applications %v", appIDs)
+	}
+	defer rows.Close()
+
+	res := make(map[int64][]sdk.Pipeline, len(appIDs))
+	for rows.Next() {
+		var appID int64
+		var pip sdk.Pipeline
+		if err := rows.Scan(&appID, &pip.ID, &pip.Name, &pip.Description, &pip.ProjectID, &pip.FromRepository); err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		res[appID] = append(res[appID], pip)
+	}
+	return res, sdk.WithStack(rows.Err())
+}
+
+func loadPipelinesBatch(db gorp.SqlExecutor, apps []*sdk.Application) error {
+	ids := make([]int64, len(apps))
+	for i := range apps {
+		ids[i] = apps[i].ID
+	}
+	pips, err := LoadPipelinesByApplicationIDs(db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range apps {
+		apps[i].Pipelines = pips[apps[i].ID]
+	}
+	return nil
+}
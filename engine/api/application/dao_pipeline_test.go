@@ -0,0 +1,63 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadOptionWithPipelines(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1"})
+	app2 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2"})
+
+	pip := sdk.Pipeline{ProjectID: proj.ID, ProjectKey: proj.Key, Name: "pip1"}
+	require.NoError(t, pipeline.InsertPipeline(db, &pip))
+	proj, _ = project.LoadByID(db, proj.ID, project.LoadOptions.WithApplications, project.LoadOptions.WithPipelines, project.LoadOptions.WithEnvironments, project.LoadOptions.WithGroups)
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		WorkflowData: sdk.WorkflowData{
+			Node: sdk.Node{
+				Type: sdk.NodeTypePipeline,
+				Context: &sdk.NodeContext{
+					PipelineID:    pip.ID,
+					ApplicationID: app1.ID,
+				},
+			},
+		},
+	}
+	require.NoError(t, workflow.RenameNode(context.TODO(), db, &w))
+	require.NoError(t, workflow.Insert(context.TODO(), db, cache, *proj, &w))
+
+	// Batched when a list is loaded
+	apps, err := application.LoadAll(db, proj.Key, application.LoadOptions.WithPipelines)
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Len(t, apps[0].Pipelines, 1)
+	require.Equal(t, "pip1", apps[0].Pipelines[0].Name)
+	require.Empty(t, apps[1].Pipelines)
+
+	res, err := application.LoadByID(db, app1.ID, application.LoadOptions.WithPipelines)
+	require.NoError(t, err)
+	require.Len(t, res.Pipelines, 1)
+	require.Equal(t, pip.ID, res.Pipelines[0].ID)
+
+	res, err = application.LoadByID(db, app2.ID, application.LoadOptions.WithPipelines)
+	require.NoError(t, err)
+	require.Empty(t, res.Pipelines)
+}
@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+const repositoryFullnameMaxLength = 256
+
+func checkRepositoryFullname(repo string) error {
+	if len(repo) > repositoryFullnameMaxLength || strings.TrimSpace(repo) != repo || !strings.Contains(repo, "/") ||
+		strings.HasPrefix(repo, "/") || strings.HasSuffix(repo, "/") {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid repository fullname %q", repo)
+	}
+	return nil
+}
+
+// LoadRepositories returns the additional repositories of an application ordered by name.
+// The main repository of the application is not included.
+func LoadRepositories(db gorp.SqlExecutor, appID int64) ([]string, error) {
+	var repos []string
+	if _, err := db.Select(&repos, "SELECT repo_fullname FROM application_repository WHERE application_id = $1 ORDER BY repo_fullname ASC", appID); err != nil {
+		return nil, sdk.WrapError(err, "cannot load repositories of application %d", appID)
+	}
+	return repos, nil
+}
+
+// AddRepository links an additional repository to an application, nothing is done if it is already linked.
+func AddRepository(ctx context.Context, db gorp.SqlExecutor, appID int64, repo string) error {
+	if err := checkRepositoryFullname(repo); err != nil {
+		return err
+	}
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
+	if _, err := db.Exec("INSERT INTO application_repository (application_id, repo_fullname) VALUES ($1, $2) ON CONFLICT DO NOTHING", appID, repo); err != nil {
+		return sdk.WrapError(err, "cannot add repository %s to application %d", repo, appID)
+	}
+	return nil
+}
+
+// RemoveRepository unlinks an additional repository from an application.
+func RemoveRepository(ctx context.Context, db gorp.SqlExecutor, appID int64, repo string) error {
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
+	res, err := db.Exec("DELETE FROM application_repository WHERE application_id = $1 AND repo_fullname = $2", appID, repo)
+	if err != nil {
+		return sdk.WrapError(err, "cannot remove repository %s from application %d", repo, appID)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	if n == 0 {
+		return sdk.NewErrorFrom(sdk.ErrNotFound, "repository %s is not linked to application %d", repo, appID)
+	}
+	return nil
+}
+
+// LoadAllByProjectIDAndRepository returns all applications of given project which main repository
+// or one of the additional repositories is given repository.
+func LoadAllByProjectIDAndRepository(ctx context.Context, db gorp.SqlExecutor, projectID int64, repo string, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1
+	AND (
+		application.repo_fullname = $2
+		OR application.id IN (
+			SELECT application_repository.application_id
+			FROM application_repository
+			WHERE application_repository.repo_fullname = $2
+		)
+	)
+	ORDER BY application.name ASC`).Args(projectID, repo)
+	return getAll(ctx, db, opts, query)
+}
@@ -0,0 +1,49 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestApplicationRepositories(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app1 := sdk.Application{Name: "my-app1", RepositoryFullname: "my/repo1"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	app2 := sdk.Application{Name: "my-app2", RepositoryFullname: "my/monorepo"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	require.Error(t, application.AddRepository(context.TODO(), db, app1.ID, "invalid"))
+	require.NoError(t, application.AddRepository(context.TODO(), db, app1.ID, "my/monorepo"))
+	require.NoError(t, application.AddRepository(context.TODO(), db, app1.ID, "my/monorepo"))
+	require.NoError(t, application.AddRepository(context.TODO(), db, app1.ID, "my/lib"))
+
+	repos, err := application.LoadRepositories(db, app1.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"my/lib", "my/monorepo"}, repos)
+
+	apps, err := application.LoadAllByProjectIDAndRepository(context.TODO(), db, proj.ID, "my/monorepo")
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, app1.ID, apps[0].ID)
+	require.Equal(t, app2.ID, apps[1].ID)
+
+	require.NoError(t, application.RemoveRepository(context.TODO(), db, app1.ID, "my/monorepo"))
+	require.True(t, sdk.ErrorIs(application.RemoveRepository(context.TODO(), db, app1.ID, "my/monorepo"), sdk.ErrNotFound))
+
+	apps, err = application.LoadAllByProjectIDAndRepository(context.TODO(), db, proj.ID, "my/monorepo")
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, app2.ID, apps[0].ID)
+}
@@ -2,13 +2,17 @@ package application_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/go-gorp/gorp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
 	"github.com/ovh/cds/engine/api/event"
 	"github.com/ovh/cds/engine/api/pipeline"
 	"github.com/ovh/cds/engine/api/project"
@@ -28,7 +32,7 @@ func TestLoadByNameAsAdmin(t *testing.T) {
 		Name: "my-app",
 	}
 
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	actual, err := application.LoadByName(db, key, "my-app")
 	test.NoError(t, err)
@@ -47,7 +51,7 @@ func TestLoadByNameAsUser(t *testing.T) {
 		Name: "my-app",
 	}
 
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	_, _ = assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
 
@@ -68,7 +72,7 @@ func TestLoadByIDAsAdmin(t *testing.T) {
 		Name: "my-app",
 	}
 
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	actual, err := application.LoadByID(db, app.ID)
 	require.NoError(t, err)
@@ -88,7 +92,7 @@ func TestLoadByIDAsUser(t *testing.T) {
 		Name: "my-app",
 	}
 
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	_, _ = assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
 
@@ -119,8 +123,8 @@ func TestLoadAllAsAdmin(t *testing.T) {
 		},
 	}
 
-	require.NoError(t, application.Insert(db, *proj, &app))
-	require.NoError(t, application.Insert(db, *proj, &app2))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
 
 	actual, err := application.LoadAll(db, proj.Key)
 	require.NoError(t, err)
@@ -146,8 +150,8 @@ func TestLoadAllAsUser(t *testing.T) {
 		Name: "my-app2",
 	}
 
-	require.NoError(t, application.Insert(db, *proj, &app))
-	require.NoError(t, application.Insert(db, *proj, &app2))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
 
 	_, _ = assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
 
@@ -168,7 +172,7 @@ func TestLoadByWorkflowID(t *testing.T) {
 		ProjectKey: proj.Key,
 		ProjectID:  proj.ID,
 	}
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	pip := sdk.Pipeline{
 		ProjectID:  proj.ID,
@@ -208,6 +212,89 @@ func TestLoadByWorkflowID(t *testing.T) {
 
 }
 
+func TestLoadAllByUsageDesc(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1"})
+	app2 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2"})
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app3"})
+
+	_, err := application.LoadAllByUsageDesc(context.TODO(), db, proj.ID, 0)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	pip := sdk.Pipeline{ProjectID: proj.ID, ProjectKey: proj.Key, Name: "pip1"}
+	require.NoError(t, pipeline.InsertPipeline(db, &pip))
+
+	proj, _ = project.LoadByID(db, proj.ID, project.LoadOptions.WithApplications, project.LoadOptions.WithPipelines, project.LoadOptions.WithEnvironments, project.LoadOptions.WithGroups)
+	for i, appID := range []int64{app2.ID, app1.ID, app2.ID} {
+		w := sdk.Workflow{
+			Name:       fmt.Sprintf("test_%d", i),
+			ProjectID:  proj.ID,
+			ProjectKey: proj.Key,
+			WorkflowData: sdk.WorkflowData{
+				Node: sdk.Node{
+					Type: sdk.NodeTypePipeline,
+					Context: &sdk.NodeContext{
+						PipelineID:    pip.ID,
+						ApplicationID: appID,
+					},
+				},
+			},
+		}
+		require.NoError(t, workflow.RenameNode(context.TODO(), db, &w))
+		require.NoError(t, workflow.Insert(context.TODO(), db, cache, *proj, &w))
+	}
+
+	apps, err := application.LoadAllByUsageDesc(context.TODO(), db, proj.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, app2.Name, apps[0].Name)
+	require.Equal(t, app1.Name, apps[1].Name)
+
+	apps, err = application.LoadAllByUsageDesc(context.TODO(), db, proj.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, app2.Name, apps[0].Name)
+}
+
+func TestUpdateVCSPasswordPrecedence(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name:               "my-app",
+		RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", User: "user", Password: "password"},
+	})
+	keepCtx := application.ContextWithKeepEmptyVCSPassword(context.TODO())
+	checkPassword := func(expected string) {
+		res, err := application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, app.ID)
+		require.NoError(t, err)
+		require.Equal(t, expected, res.RepositoryStrategy.Password)
+	}
+
+	// Placeholder keeps the stored password with or without the flag
+	app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
+	require.NoError(t, application.Update(context.TODO(), db, app))
+	checkPassword("password")
+
+	// Empty keeps the stored password only with the flag
+	app.RepositoryStrategy.Password = ""
+	require.NoError(t, application.Update(keepCtx, db, app))
+	checkPassword("password")
+
+	// Explicit value is stored with or without the flag
+	app.RepositoryStrategy.Password = "password2"
+	require.NoError(t, application.Update(keepCtx, db, app))
+	checkPassword("password2")
+
+	app.RepositoryStrategy.Password = ""
+	require.NoError(t, application.Update(context.TODO(), db, app))
+	checkPassword("")
+}
+
 func TestWithRepositoryStrategy(t *testing.T) {
 	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
 
@@ -219,7 +306,7 @@ func TestWithRepositoryStrategy(t *testing.T) {
 		ProjectKey: proj.Key,
 		ProjectID:  proj.ID,
 	}
-	require.NoError(t, application.Insert(db, *proj, app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	app.RepositoryStrategy = sdk.RepositoryStrategy{
 		Branch:         "{{.git.branch}}",
@@ -230,7 +317,7 @@ func TestWithRepositoryStrategy(t *testing.T) {
 		SSHKeyContent:  "content",
 	}
 
-	require.NoError(t, application.Update(db, app))
+	require.NoError(t, application.Update(context.TODO(), db, app))
 	require.Equal(t, "user", app.RepositoryStrategy.User)
 	require.Equal(t, sdk.PasswordPlaceholder, app.RepositoryStrategy.Password)
 	require.Equal(t, "", app.RepositoryStrategy.SSHKeyContent) // it depends on the connection type
@@ -240,9 +327,9 @@ func TestWithRepositoryStrategy(t *testing.T) {
 	app, err = application.LoadByID(db, app.ID)
 	require.NoError(t, err)
 	app.RepositoryStrategy.Password = "password2"
-	require.NoError(t, application.Update(db, app))
+	require.NoError(t, application.Update(context.TODO(), db, app))
 
-	app, err = application.LoadByIDWithClearVCSStrategyPassword(db, app.ID)
+	app, err = application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, app.ID)
 	require.NoError(t, err)
 	require.Equal(t, "user", app.RepositoryStrategy.User)
 	require.Equal(t, "password2", app.RepositoryStrategy.Password)
@@ -258,7 +345,7 @@ func TestWithRepositoryStrategy(t *testing.T) {
 	app.RepositoryStrategy.SSHKeyContent = "ssh_key"
 	app.RepositoryStrategy.SSHKey = "ssh_key"
 
-	require.NoError(t, application.Update(db, app))
+	require.NoError(t, application.Update(context.TODO(), db, app))
 	require.Equal(t, "user", app.RepositoryStrategy.User)
 	require.Equal(t, sdk.PasswordPlaceholder, app.RepositoryStrategy.Password)
 	require.Equal(t, "", app.RepositoryStrategy.SSHKeyContent) // it depends on the connection type
@@ -277,8 +364,8 @@ func Test_LoadAllVCStrategyAllApps(t *testing.T) {
 	app2 := &sdk.Application{Name: "my-app2", ProjectKey: proj.Key, ProjectID: proj.ID, RepositoryStrategy: sdk.RepositoryStrategy{
 		Password: "secret2",
 	}}
-	require.NoError(t, application.Insert(db, *proj, app1))
-	require.NoError(t, application.Insert(db, *proj, app2))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app2))
 
 	apps, err := application.LoadAllByIDsWithDecryption(db, []int64{app1.ID, app2.ID})
 	require.NoError(t, err)
@@ -301,3 +388,756 @@ func Test_LoadAllVCStrategyAllApps(t *testing.T) {
 	require.True(t, app1Check)
 	require.True(t, app2Check)
 }
+
+func TestLoadByIDWithClearVCSStrategyPasswordAudit(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := &sdk.Application{Name: "my-app", RepositoryStrategy: sdk.RepositoryStrategy{
+		ConnectionType: "https",
+		User:           "user",
+		Password:       "secret",
+	}}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+
+	var accesses []application.VCSPasswordAccess
+	application.SetVCSPasswordAccessSink(func(_ context.Context, access application.VCSPasswordAccess) {
+		accesses = append(accesses, access)
+	})
+	t.Cleanup(func() { application.SetVCSPasswordAccessSink(nil) })
+
+	ctx := application.ContextWithAccessor(context.TODO(), "my-user")
+	_, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Len(t, accesses, 0)
+
+	_, err = application.LoadByIDWithClearVCSStrategyPassword(ctx, db, app.ID)
+	require.NoError(t, err)
+	_, err = application.LoadByNameWithClearVCSStrategyPassword(ctx, db, proj.Key, app.Name)
+	require.NoError(t, err)
+
+	require.Len(t, accesses, 2)
+	for _, a := range accesses {
+		require.Equal(t, app.ID, a.ApplicationID)
+		require.Equal(t, "my-user", a.Accessor)
+		require.False(t, a.Timestamp.IsZero())
+	}
+}
+
+func TestInvalidProjectID(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	for _, id := range []int64{0, -1} {
+		app := sdk.Application{Name: "my-app"}
+		err := application.Insert(context.TODO(), db, sdk.Project{ID: id, Key: sdk.RandomString(10)}, &app)
+		require.Error(t, err)
+		require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+		_, err = application.LoadAllNames(context.TODO(), db, id)
+		require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+		_, err = application.LoadAllWithExpiringKeys(context.TODO(), db, id, time.Now())
+		require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+	}
+
+	_, err := application.LoadAll(db, "")
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	apps, err := application.LoadAll(db, proj.Key)
+	require.NoError(t, err)
+	require.Len(t, apps, 0)
+}
+
+func TestExistsCache(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	application.EnableExistsCache(time.Minute, 10)
+	t.Cleanup(application.DisableExistsCache)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	exists, err := application.Exists(db, proj.Key, "my-app")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	exists, err = application.Exists(db, proj.Key, "my-app")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	app.Name = "my-app-renamed"
+	require.NoError(t, application.Update(context.TODO(), db, &app))
+
+	exists, err = application.Exists(db, proj.Key, "my-app")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, application.DeleteApplication(context.TODO(), db, app.ID))
+
+	exists, err = application.Exists(db, proj.Key, "my-app-renamed")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestLoadAllWithBestEffort(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := sdk.Application{Name: "my-app1", Description: "fail"}
+	app2 := sdk.Application{Name: "my-app2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	failingOption := func(_ gorp.SqlExecutor, app *sdk.Application) error {
+		if app.Description == "fail" {
+			return sdk.NewErrorFrom(sdk.ErrUnknownError, "failing option")
+		}
+		app.Icon = "loaded"
+		return nil
+	}
+
+	_, err := application.LoadAll(db, proj.Key, &failingOption)
+	require.Error(t, err)
+
+	apps, err := application.LoadAll(db, proj.Key, &failingOption, application.LoadOptions.WithBestEffort)
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, "my-app1", apps[0].Name)
+	require.Equal(t, "", apps[0].Icon)
+	require.Equal(t, "my-app2", apps[1].Name)
+	require.Equal(t, "loaded", apps[1].Icon)
+}
+
+func TestLoadAllNamesAndRepositoriesByProjectID(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := sdk.Application{Name: "my-app1", FromRepository: "ssh://git@github.com/ovh/cds.git"}
+	app2 := sdk.Application{Name: "my-app2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	res, err := application.LoadAllNamesAndRepositoriesByProjectID(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, []application.NameAndRepository{
+		{ID: app1.ID, Name: "my-app1", FromRepository: "ssh://git@github.com/ovh/cds.git"},
+		{ID: app2.ID, Name: "my-app2"},
+	}, res)
+}
+
+func TestExistsInProjects(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key1 := sdk.RandomString(10)
+	proj1 := assets.InsertTestProject(t, db, cache, key1, key1)
+	key2 := sdk.RandomString(10)
+	proj2 := assets.InsertTestProject(t, db, cache, key2, key2)
+
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj1, &app))
+
+	res, err := application.ExistsInProjects(db, []int64{proj1.ID, proj2.ID}, "my-app")
+	require.NoError(t, err)
+	require.Equal(t, map[int64]bool{proj1.ID: true, proj2.ID: false}, res)
+
+	res, err = application.ExistsInProjects(db, []int64{proj1.ID}, "unknown")
+	require.NoError(t, err)
+	require.Equal(t, map[int64]bool{proj1.ID: false}, res)
+}
+
+func TestLoadAllUnusedSince(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := sdk.Application{Name: "my-app1"}
+	app2 := sdk.Application{Name: "my-app2"}
+	app3 := sdk.Application{Name: "my-app3"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app3))
+
+	now := time.Now()
+	require.NoError(t, application.UpdateLastUsed(db, app1.ID, now))
+	require.NoError(t, application.UpdateLastUsed(db, app2.ID, now.Add(-30*24*time.Hour)))
+	// An older date should not override a newer one
+	require.NoError(t, application.UpdateLastUsed(db, app1.ID, now.Add(-30*24*time.Hour)))
+
+	apps, err := application.LoadAllUnusedSince(context.TODO(), db, proj.ID, now.Add(-7*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, app2.ID, apps[0].ID)
+	require.Equal(t, app3.ID, apps[1].ID)
+
+	// Signature is still valid
+	_, err = application.LoadByID(db, app1.ID)
+	require.NoError(t, err)
+}
+
+func TestLoadLegacyRepositoryStrategy(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	// Legacy fixture stored with camel case keys
+	legacy := map[string]string{
+		"connectionType": "https",
+		"username":       "user",
+		"password":       "vcs_secret",
+		"defaultBranch":  "master",
+	}
+	var cipher []byte
+	require.NoError(t, gorpmapping.Mapper.Encrypt(legacy, &cipher, []interface{}{app.ProjectID, app.Name}))
+	_, err := db.Exec("UPDATE application SET cipher_vcs_strategy = $1 WHERE id = $2", cipher, app.ID)
+	require.NoError(t, err)
+
+	res, err := application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "https", res.RepositoryStrategy.ConnectionType)
+	require.Equal(t, "user", res.RepositoryStrategy.User)
+	require.Equal(t, "vcs_secret", res.RepositoryStrategy.Password)
+	require.Equal(t, "master", res.RepositoryStrategy.DefaultBranch)
+}
+
+func TestCountByVCSHost(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	for _, a := range []sdk.Application{
+		{Name: "app1", FromRepository: "https://github.com/ovh/cds.git"},
+		{Name: "app2", FromRepository: "git@github.com:ovh/cds.git"},
+		{Name: "app3", FromRepository: "ssh://git@ghe.example.com:7999/ovh/cds.git"},
+		{Name: "app4", VCSServer: "gitlab"},
+		{Name: "app5"},
+	} {
+		app := a
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	}
+
+	res, err := application.CountByVCSHost(db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{
+		"github.com":      2,
+		"ghe.example.com": 1,
+		"gitlab":          1,
+		"":                1,
+	}, res)
+}
+
+func TestCountByConnectionType(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+	application.SetSecretResolver(func(ctx context.Context, ref string) (string, error) { return "", nil })
+	t.Cleanup(func() { application.SetSecretResolver(nil) })
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	var ids []int64
+	for _, a := range []sdk.Application{
+		{Name: "app1", RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "ssh", SSHKey: "app-key"}},
+		{Name: "app2", RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", User: "user", Password: "pwd"}},
+		{Name: "app3", RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", PasswordRef: "vault://cds/app3"}},
+		{Name: "app4", RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", User: "user", Password: "pwd"}},
+		{Name: "app5"},
+	} {
+		app := a
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+		ids = append(ids, app.ID)
+	}
+
+	// Simulate an application written before the connection type column was added
+	_, err := db.Exec("UPDATE application SET vcs_connection_type = NULL WHERE id = $1", ids[3])
+	require.NoError(t, err)
+
+	res, err := application.CountByConnectionType(db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{
+		"ssh":                               1,
+		"https":                             2,
+		application.ConnectionTypeSecretRef: 1,
+		"":                                  1,
+	}, res)
+}
+
+func TestHasVCSPassword(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := sdk.Application{
+		Name: "my-app1",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "user",
+			Password:       "vcs_secret",
+		},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	app2 := sdk.Application{Name: "my-app2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+
+	has, err := application.HasVCSPassword(db, app1.ID)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	has, err = application.HasVCSPassword(db, app2.ID)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// An application not written since the flag was added is decrypted once to set it
+	_, err = db.Exec("UPDATE application SET vcs_password_set = NULL WHERE id = $1", app1.ID)
+	require.NoError(t, err)
+	has, err = application.HasVCSPassword(db, app1.ID)
+	require.NoError(t, err)
+	require.True(t, has)
+	set, err := db.SelectNullInt("SELECT vcs_password_set::int FROM application WHERE id = $1", app1.ID)
+	require.NoError(t, err)
+	require.True(t, set.Valid)
+
+	app1.RepositoryStrategy.Password = ""
+	require.NoError(t, application.Update(context.TODO(), db, &app1))
+	has, err = application.HasVCSPassword(db, app1.ID)
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func TestInsertWithCanceledContext(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	app := sdk.Application{Name: "my-app"}
+	require.Error(t, application.Insert(ctx, db, *proj, &app))
+
+	exists, err := application.Exists(db, proj.Key, "my-app")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	app.Description = "my description"
+	require.Error(t, application.Update(ctx, db, &app))
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Empty(t, res.Description)
+}
+
+func TestInsertWithResult(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app := sdk.Application{Name: "my-app"}
+	res, err := application.InsertWithResult(context.TODO(), db, *proj, &app)
+	require.NoError(t, err)
+	require.NotZero(t, res.ID)
+	require.Equal(t, app.ID, res.ID)
+	require.Equal(t, app.LastModified, res.Created)
+	require.True(t, res.Verified)
+	require.False(t, res.Replayed)
+
+	_, err = application.InsertWithResult(context.TODO(), db, *proj, &sdk.Application{Name: "my-app"})
+	require.Error(t, err)
+}
+
+func TestLoadRepositoryIndex(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	var ids []int64
+	for _, a := range []sdk.Application{
+		{Name: "app1", RepositoryFullname: "ovh/cds"},
+		{Name: "app2", RepositoryFullname: "ovh/cds"},
+		{Name: "app3", RepositoryFullname: "ovh/venom"},
+		{Name: "app4"},
+	} {
+		app := a
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+		ids = append(ids, app.ID)
+	}
+
+	res, err := application.LoadRepositoryIndex(db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]int64{
+		"ovh/cds":   {ids[0], ids[1]},
+		"ovh/venom": {ids[2]},
+	}, res)
+}
+
+func TestLoadAllByRepositoryPrefix(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	for _, a := range []sdk.Application{
+		{Name: "my-app1", RepositoryFullname: "my_org/repo1"},
+		{Name: "my-app2", RepositoryFullname: "my_org/repo2"},
+		{Name: "my-app3", RepositoryFullname: "myXorg/repo3"},
+		{Name: "my-app4"},
+	} {
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, &a))
+	}
+
+	apps, err := application.LoadAllByRepositoryPrefix(context.TODO(), db, proj.ID, "my_org/")
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, "my-app1", apps[0].Name)
+	require.Equal(t, "my-app2", apps[1].Name)
+
+	_, err = application.LoadAllByRepositoryPrefix(context.TODO(), db, proj.ID, "")
+	require.Error(t, err)
+}
+
+func TestFindOrphanedByProject(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	apps, err := application.FindOrphanedByProject(context.TODO(), db)
+	require.NoError(t, err)
+	for _, a := range apps {
+		require.NotEqual(t, app.ID, a.ID)
+	}
+}
+
+func TestLoadWithExistingProject(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	// Orphaned applications can only exist from before the foreign key, drop it in a transaction that is rolled back
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback() // nolint
+	_, err = tx.Exec("ALTER TABLE application DROP CONSTRAINT fk_application_project")
+	require.NoError(t, err)
+	orphan := sdk.Application{Name: "my-orphan"}
+	require.NoError(t, application.Insert(context.TODO(), tx, sdk.Project{ID: proj.ID + 1000000, Key: sdk.RandomString(10)}, &orphan))
+
+	// Default loads are unchanged
+	apps, err := application.LoadAllByIDs(tx, []int64{app.ID, orphan.ID})
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	res, err := application.LoadByID(tx, orphan.ID)
+	require.NoError(t, err)
+	require.Empty(t, res.ProjectKey)
+
+	// Skipped applications are not left in the returned slice
+	apps, err = application.LoadAllByIDs(tx, []int64{app.ID, orphan.ID}, application.LoadOptions.WithExistingProject)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, app.ID, apps[0].ID)
+	_, err = application.LoadByID(tx, orphan.ID, application.LoadOptions.WithExistingProject)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+	_, err = application.LoadByID(tx, app.ID, application.LoadOptions.WithExistingProject)
+	require.NoError(t, err)
+
+	orphans, err := application.FindOrphanedByProject(context.TODO(), tx)
+	require.NoError(t, err)
+	var found bool
+	for _, a := range orphans {
+		found = found || a.ID == orphan.ID
+	}
+	require.True(t, found)
+}
+
+func TestLoadByIDWithETag(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	res, etag1, err := application.LoadByIDWithETag(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, app.ID, res.ID)
+
+	_, etag2, err := application.LoadByIDWithETag(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, etag1, etag2)
+
+	res.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	_, etag3, err := application.LoadByIDWithETag(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, etag1, etag3)
+
+	// Columns that are not part of the content hash change the ETag too
+	require.NoError(t, application.SetColor(db, app.ID, "#ff0000"))
+	res, etag4, err := application.LoadByIDWithETag(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, etag3, etag4)
+	err = application.UpdateWithETag(context.TODO(), db, res, etag3)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrConflictData))
+}
+
+func TestCountByRepository(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	for _, a := range []sdk.Application{
+		{Name: "my-app1", FromRepository: "https://github.com/my/repo1.git"},
+		{Name: "my-app2", FromRepository: "https://github.com/my/repo1.git"},
+		{Name: "my-app3", FromRepository: "https://github.com/my/repo2.git"},
+		{Name: "my-app4"},
+	} {
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, &a))
+	}
+
+	counts, err := application.CountByRepository(db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{
+		"https://github.com/my/repo1.git": 2,
+		"https://github.com/my/repo2.git": 1,
+	}, counts)
+}
+
+func TestLoadIconConstrained(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	icon := sdk.IconFormat + "png;base64,aWNvbg=="
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app", Icon: icon})
+
+	_, err := application.LoadIconConstrained(db, app.ID, 0)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	res, err := application.LoadIconConstrained(db, app.ID, len(icon))
+	require.NoError(t, err)
+	require.Equal(t, icon, res)
+
+	res, err = application.LoadIconConstrained(db, app.ID, len(icon)-1)
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
+
+func TestLoadRecentlyModified(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	now := time.Now()
+	for i, name := range []string{"my-app1", "my-app2", "my-app3"} {
+		app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: name})
+		_, err := db.Exec("UPDATE application SET last_modified = $2 WHERE id = $1", app.ID, now.Add(-time.Duration(3-i)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	_, _, err := application.LoadRecentlyModified(context.TODO(), db, proj.ID, now, 0, 0)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	apps, hasMore, err := application.LoadRecentlyModified(context.TODO(), db, proj.ID, now, 0, 2)
+	require.NoError(t, err)
+	require.True(t, hasMore)
+	require.Len(t, apps, 2)
+	require.Equal(t, "my-app3", apps[0].Name)
+	require.Equal(t, "my-app2", apps[1].Name)
+
+	apps, hasMore, err = application.LoadRecentlyModified(context.TODO(), db, proj.ID, apps[1].LastModified, apps[1].ID, 2)
+	require.NoError(t, err)
+	require.False(t, hasMore)
+	require.Len(t, apps, 1)
+	require.Equal(t, "my-app1", apps[0].Name)
+
+	// Applications modified at the same time are split between pages without being skipped
+	_, err = db.Exec("UPDATE application SET last_modified = $2 WHERE project_id = $1", proj.ID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	var names []string
+	beforeTime, beforeID := now, int64(0)
+	for {
+		apps, hasMore, err = application.LoadRecentlyModified(context.TODO(), db, proj.ID, beforeTime, beforeID, 1)
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+		names = append(names, apps[0].Name)
+		if !hasMore {
+			break
+		}
+		beforeTime, beforeID = apps[0].LastModified, apps[0].ID
+	}
+	require.ElementsMatch(t, []string{"my-app1", "my-app2", "my-app3"}, names)
+}
+
+func TestDetachFromRepository(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1", FromRepository: "https://my-repo"})
+	app2 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2", FromRepository: "https://my-repo"})
+	app3 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app3"})
+
+	_, hashBefore, err := application.LoadByIDWithHash(context.TODO(), db, app1.ID)
+	require.NoError(t, err)
+
+	n, err := application.DetachFromRepository(context.TODO(), db, proj.ID, []int64{app1.ID, app3.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	// Signature is still valid as loaders return only valid applications
+	res, hashAfter, err := application.LoadByIDWithHash(context.TODO(), db, app1.ID)
+	require.NoError(t, err)
+	require.Empty(t, res.FromRepository)
+	require.NotEqual(t, hashBefore, hashAfter)
+	res, err = application.LoadByID(db, app2.ID)
+	require.NoError(t, err)
+	require.Equal(t, "https://my-repo", res.FromRepository)
+
+	// Read only applications are skipped
+	require.NoError(t, application.SetReadOnly(db, app2.ID, true))
+	n, err = application.DetachFromRepository(context.TODO(), db, proj.ID, []int64{app2.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), n)
+	res, err = application.LoadByID(db, app2.ID)
+	require.NoError(t, err)
+	require.Equal(t, "https://my-repo", res.FromRepository)
+}
+
+func TestLoadAllByMetadata(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1", Metadata: sdk.Metadata{"external-id": "123", "team": "a"}})
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2", Metadata: sdk.Metadata{"external-id": "456", "team": "a"}})
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app3"})
+
+	_, err := application.LoadAllByMetadata(context.TODO(), db, proj.ID, "", "a")
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	apps, err := application.LoadAllByMetadata(context.TODO(), db, proj.ID, "team", "a")
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, "my-app1", apps[0].Name)
+	require.Equal(t, "my-app2", apps[1].Name)
+
+	apps, err = application.LoadAllByMetadata(context.TODO(), db, proj.ID, "external-id", "456")
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, "my-app2", apps[0].Name)
+
+	apps, err = application.LoadAllByMetadata(context.TODO(), db, proj.ID, "external-id", "4")
+	require.NoError(t, err)
+	require.Empty(t, apps)
+}
+
+func TestLoadAllByIDsPartial(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1"})
+	app2 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2"})
+	app3 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app3"})
+
+	_, err := db.Exec("UPDATE application SET name = 'my-corrupted-app' WHERE id = $1", app2.ID)
+	require.NoError(t, err)
+
+	res, err := application.LoadAllByIDsPartial(context.TODO(), db, []int64{app3.ID, app2.ID, app1.ID, app1.ID, -1})
+	require.NoError(t, err)
+	require.Len(t, res.Applications, 2)
+	require.Equal(t, "my-app1", res.Applications[0].Name)
+	require.Equal(t, "my-app3", res.Applications[1].Name)
+	require.Equal(t, []int64{app2.ID}, res.Corrupted)
+	require.Equal(t, []int64{-1}, res.NotFound)
+}
+
+func TestLoadVCSServer(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1", VCSServer: "github"})
+	app2 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2"})
+
+	server, err := application.LoadVCSServer(db, app1.ID)
+	require.NoError(t, err)
+	require.Equal(t, "github", server)
+
+	server, err = application.LoadVCSServer(db, app2.ID)
+	require.NoError(t, err)
+	require.Empty(t, server)
+
+	_, err = application.LoadVCSServer(db, -1)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
+
+func TestLoadAllNamesAndIconCancellation(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	names, err := application.LoadAllNames(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, names, 1)
+	_, err = application.LoadIcon(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err = application.LoadAllNames(ctx, db, proj.ID)
+	require.Error(t, err)
+	_, err = application.LoadIcon(ctx, db, app.ID)
+	require.Error(t, err)
+}
+
+func TestUpdateNotFound(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	for _, id := range []int64{0, app.ID + 1000} {
+		a := sdk.Application{ID: id, Name: "my-app", ProjectID: proj.ID, ProjectKey: proj.Key}
+		err := application.Update(context.TODO(), db, &a)
+		require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound), "update of application %d should fail", id)
+	}
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-app", res.Name)
+}
+
+func TestFindCaseCollisions(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	for _, name := range []string{"my-app", "My-App", "MY-APP", "my-other", "Other-App", "other-app"} {
+		assets.InsertTestApplication(t, db, proj, sdk.Application{Name: name})
+	}
+
+	res, err := application.FindCaseCollisions(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"MY-APP", "My-App", "my-app"},
+		{"Other-App", "other-app"},
+	}, res)
+}
@@ -3,6 +3,7 @@ package application
 import (
 	"context"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-gorp/gorp"
@@ -14,6 +15,23 @@ import (
 	"github.com/ovh/cds/sdk/log"
 )
 
+// maxVariables is the maximum number of variables of an application, 0 means unlimited.
+var maxVariables int64
+
+// SetMaxVariables sets the maximum number of variables of an application checked by InsertVariable, 0 means unlimited.
+func SetMaxVariables(max int64) {
+	atomic.StoreInt64(&maxVariables, max)
+}
+
+// LoadVariableCount returns the number of variables of an application.
+func LoadVariableCount(db gorp.SqlExecutor, appID int64) (int64, error) {
+	count, err := db.SelectInt("SELECT COUNT(1) FROM application_variable WHERE application_id = $1", appID)
+	if err != nil {
+		return 0, sdk.WrapError(err, "cannot count variables of application %d", appID)
+	}
+	return count, nil
+}
+
 type dbApplicationVariable struct {
 	gorpmapper.SignedEntity
 	ID            int64  `db:"id"`
@@ -114,6 +132,43 @@ func LoadAllVariablesWithDecrytion(db gorp.SqlExecutor, appID int64) ([]sdk.Appl
 	return loadAllVariables(db, query, gorpmapping.GetOptions.WithDecryption)
 }
 
+// LoadAllVariablesWithDecryptionSkipUndecryptable returns all the variables of given application with decrypted
+// secrets. A secret that can't be decrypted or which decrypted signature is invalid doesn't fail the load, its
+// variable is returned marked as undecryptable. Any other error is returned.
+func LoadAllVariablesWithDecryptionSkipUndecryptable(db gorp.SqlExecutor, appID int64) ([]sdk.ApplicationVariable, error) {
+	vars, err := LoadAllVariablesWithDecrytion(db, appID)
+	if err == nil {
+		return vars, nil
+	}
+	if sdk.Cause(err) != gorpmapper.ErrDecryption {
+		return nil, err
+	}
+
+	// At least one secret can't be decrypted, decrypt them one by one to find which
+	vars, err = LoadAllVariables(db, appID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vars {
+		if !sdk.NeedPlaceholder(vars[i].Type) {
+			continue
+		}
+		v, err := LoadVariableWithDecryption(db, appID, vars[i].ID, vars[i].Name)
+		// The variable was just listed with a valid signature, not found means its decrypted signature is invalid
+		if sdk.Cause(err) == gorpmapper.ErrDecryption || sdk.ErrorIs(err, sdk.ErrNotFound) {
+			log.Warning(context.Background(), "application.LoadAllVariablesWithDecryptionSkipUndecryptable> cannot decrypt variable %d of application %d: %v", vars[i].ID, appID, err)
+			vars[i].Value = ""
+			vars[i].Undecryptable = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		vars[i] = *v
+	}
+	return vars, nil
+}
+
 func loadVariable(db gorp.SqlExecutor, q gorpmapping.Query, opts ...gorpmapping.GetOptionFunc) (*sdk.ApplicationVariable, error) {
 	var v dbApplicationVariable
 	found, err := gorpmapping.Get(context.Background(), db, q, &v, opts...)
@@ -160,16 +215,100 @@ func DeleteAllVariables(db gorp.SqlExecutor, applicationID int64) error {
 	return nil
 }
 
-// InsertVariable Insert a new variable in the given application
-func InsertVariable(db gorpmapper.SqlExecutorWithTx, appID int64, v *sdk.ApplicationVariable, u sdk.Identifiable) error {
+// checkVariableNameAvailable returns sdk.ErrVariableExists if another variable of the application has the same
+// name ignoring case, variables that only differ by case are ambiguous at runtime.
+func checkVariableNameAvailable(db gorp.SqlExecutor, appID, varID int64, name string) error {
+	existing, err := db.SelectNullStr(`
+	SELECT var_name
+	FROM application_variable
+	WHERE application_id = $1 AND lower(var_name) = lower($2) AND id <> $3
+	LIMIT 1`, appID, name, varID)
+	if err != nil {
+		return sdk.WrapError(err, "cannot check variable name %s", name)
+	}
+	if existing.Valid {
+		return sdk.NewErrorFrom(sdk.ErrVariableExists, "variable %s conflicts with existing variable %s", name, existing.String)
+	}
+	return nil
+}
+
+func isVariableNameViolation(err error) bool {
+	e, ok := sdk.Cause(err).(*pq.Error)
+	return ok && e.Code == gorpmapper.ViolateUniqueKeyPGCode && e.Constraint == "idx_application_variable_lower_name"
+}
+
+// DuplicateVariables are variable names of an application that only differ by case.
+type DuplicateVariables struct {
+	ApplicationID   int64    `json:"application_id"`
+	ApplicationName string   `json:"application_name"`
+	Names           []string `json:"names"`
+}
+
+// FindDuplicateVariables returns the variables of the applications of a project that only differ by case, ordered by
+// application name. It is used to clean existing data, nothing is written.
+func FindDuplicateVariables(ctx context.Context, db gorp.SqlExecutor, projectID int64) ([]DuplicateVariables, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`
+	SELECT application.id, application.name, array_agg(application_variable.var_name ORDER BY application_variable.var_name)
+	FROM application_variable
+	JOIN application ON application.id = application_variable.application_id
+	WHERE application.project_id = $1
+	GROUP BY application.id, application.name, lower(application_variable.var_name)
+	HAVING COUNT(1) > 1
+	ORDER BY application.name, lower(application_variable.var_name)`, projectID)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot find duplicate variables for project %d", projectID)
+	}
+	defer rows.Close()
+
+	var res []DuplicateVariables
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		var d DuplicateVariables
+		var names pq.StringArray
+		if err := rows.Scan(&d.ApplicationID, &d.ApplicationName, &names); err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		d.Names = names
+		res = append(res, d)
+	}
+	return res, sdk.WithStack(rows.Err())
+}
+
+// InsertVariable Insert a new variable in the given application, sdk.ErrForbidden is returned if the application is
+// not writable for given context.
+func InsertVariable(ctx context.Context, db gorpmapper.SqlExecutorWithTx, appID int64, v *sdk.ApplicationVariable, u sdk.Identifiable) error {
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
 	//Check variable name
 	rx := sdk.NamePatternRegex
 	if !rx.MatchString(v.Name) {
 		return sdk.NewErrorFrom(sdk.ErrInvalidName, "variable name should match pattern %s", sdk.NamePattern)
 	}
+	if max := atomic.LoadInt64(&maxVariables); max > 0 {
+		// Concurrent inserts could both pass the count before one of them is committed
+		if err := LockApplication(ctx, db, appID); err != nil {
+			return err
+		}
+		count, err := LoadVariableCount(db, appID)
+		if err != nil {
+			return err
+		}
+		if count >= max {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "application %d cannot have more than %d variables", appID, max)
+		}
+	}
+	if err := checkVariableNameAvailable(db, appID, 0, v.Name); err != nil {
+		return err
+	}
 	dbVar := newDBApplicationVariable(*v, appID)
-	err := gorpmapping.InsertAndSign(context.Background(), db, &dbVar)
-	if err != nil && strings.Contains(err.Error(), "application_variable_pkey") {
+	err := gorpmapping.InsertAndSign(ctx, db, &dbVar)
+	if err != nil && (strings.Contains(err.Error(), "application_variable_pkey") || isVariableNameViolation(err)) {
 		return sdk.WithStack(sdk.ErrVariableExists)
 	}
 	if err != nil {
@@ -193,16 +332,27 @@ func InsertVariable(db gorpmapper.SqlExecutorWithTx, appID int64, v *sdk.Applica
 	return nil
 }
 
-// UpdateVariable Update a variable in the given application
-func UpdateVariable(db gorpmapper.SqlExecutorWithTx, appID int64, variable *sdk.ApplicationVariable, variableBefore *sdk.ApplicationVariable, u sdk.Identifiable) error {
+// UpdateVariable Update a variable in the given application, sdk.ErrForbidden is returned if the application is not
+// writable for given context.
+func UpdateVariable(ctx context.Context, db gorpmapper.SqlExecutorWithTx, appID int64, variable *sdk.ApplicationVariable, variableBefore *sdk.ApplicationVariable, u sdk.Identifiable) error {
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
 	rx := sdk.NamePatternRegex
 	if !rx.MatchString(variable.Name) {
 		return sdk.NewErrorFrom(sdk.ErrInvalidName, "variable name should match pattern %s", sdk.NamePattern)
 	}
 
+	if err := checkVariableNameAvailable(db, appID, variable.ID, variable.Name); err != nil {
+		return err
+	}
+
 	dbVar := newDBApplicationVariable(*variable, appID)
 
-	if err := gorpmapping.UpdateAndSign(context.Background(), db, &dbVar); err != nil {
+	if err := gorpmapping.UpdateAndSign(ctx, db, &dbVar); err != nil {
+		if isVariableNameViolation(err) {
+			return sdk.WithStack(sdk.ErrVariableExists)
+		}
 		return err
 	}
 
@@ -229,8 +379,12 @@ func UpdateVariable(db gorpmapper.SqlExecutorWithTx, appID int64, variable *sdk.
 	return nil
 }
 
-// DeleteVariable Delete a variable from the given pipeline
-func DeleteVariable(db gorp.SqlExecutor, appID int64, variable *sdk.ApplicationVariable, u sdk.Identifiable) error {
+// DeleteVariable Delete a variable from the given application, sdk.ErrForbidden is returned if the application is not
+// writable for given context.
+func DeleteVariable(ctx context.Context, db gorp.SqlExecutor, appID int64, variable *sdk.ApplicationVariable, u sdk.Identifiable) error {
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
 	query := `DELETE FROM application_variable
 		  WHERE application_variable.application_id = $1 AND application_variable.var_name = $2`
 	result, err := db.Exec(query, appID, variable.Name)
@@ -272,7 +426,7 @@ func loadAllVariablesForApps(ctx context.Context, db gorp.SqlExecutor, appsID []
 		SELECT *
 		FROM application_variable
 		WHERE application_id = ANY($1)
-		ORDER BY application_id
+		ORDER BY application_id, var_name
 	`).Args(pq.Int64Array(appsID))
 	if err := gorpmapping.GetAll(ctx, db, query, &res, opts...); err != nil {
 		return nil, err
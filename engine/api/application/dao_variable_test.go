@@ -9,6 +9,7 @@ import (
 	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/test"
 	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/gorpmapper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,15 +25,15 @@ func Test_DAOVariable(t *testing.T) {
 
 	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
 
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	v1 := &sdk.ApplicationVariable{Name: "clear", Type: sdk.TextVariable, Value: "clear_value"}
 	v2 := &sdk.ApplicationVariable{Name: "secret", Type: sdk.SecretVariable, Value: "secret_value"}
 
-	require.NoError(t, application.InsertVariable(db, app.ID, v1, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, v1, u))
 	assert.Equal(t, "clear_value", v1.Value)
 
-	require.NoError(t, application.InsertVariable(db, app.ID, v2, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, v2, u))
 	assert.Equal(t, sdk.PasswordPlaceholder, v2.Value)
 
 	vs, err := application.LoadAllVariables(db, app.ID)
@@ -45,7 +46,7 @@ func Test_DAOVariable(t *testing.T) {
 	assert.Equal(t, "clear_value", vs[0].Value)
 	assert.Equal(t, "secret_value", vs[1].Value)
 
-	require.NoError(t, application.UpdateVariable(db, app.ID, &vs[1], &vs[1], u))
+	require.NoError(t, application.UpdateVariable(context.TODO(), db, app.ID, &vs[1], &vs[1], u))
 
 	v1, err = application.LoadVariable(db, app.ID, "clear")
 	require.NoError(t, err)
@@ -59,7 +60,7 @@ func Test_DAOVariable(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "secret_value", v2.Value)
 
-	require.NoError(t, application.DeleteVariable(db, app.ID, v2, u))
+	require.NoError(t, application.DeleteVariable(context.TODO(), db, app.ID, v2, u))
 
 	require.NoError(t, application.DeleteAllVariables(db, app.ID))
 
@@ -75,21 +76,21 @@ func Test_DAOAllVarsAllProjects(t *testing.T) {
 	app1 := sdk.Application{
 		Name: "my-app",
 	}
-	require.NoError(t, application.Insert(db, *proj, &app1))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
 	app2 := sdk.Application{
 		Name: "my-app2",
 	}
-	require.NoError(t, application.Insert(db, *proj, &app2))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
 
 	v1 := &sdk.ApplicationVariable{Name: "clear", Type: sdk.TextVariable, Value: "clear_value1"}
 	v2 := &sdk.ApplicationVariable{Name: "secret", Type: sdk.SecretVariable, Value: "secret_value1"}
 	v3 := &sdk.ApplicationVariable{Name: "clear", Type: sdk.TextVariable, Value: "clear_value2"}
 	v4 := &sdk.ApplicationVariable{Name: "secret", Type: sdk.SecretVariable, Value: "secret_value2"}
 
-	require.NoError(t, application.InsertVariable(db, app1.ID, v1, u))
-	require.NoError(t, application.InsertVariable(db, app1.ID, v2, u))
-	require.NoError(t, application.InsertVariable(db, app2.ID, v3, u))
-	require.NoError(t, application.InsertVariable(db, app2.ID, v4, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app1.ID, v1, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app1.ID, v2, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app2.ID, v3, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app2.ID, v4, u))
 
 	vars, err := application.LoadAllVariablesForAppsWithDecryption(context.TODO(), db, []int64{app1.ID, app2.ID})
 	require.NoError(t, err)
@@ -117,3 +118,152 @@ func Test_DAOAllVarsAllProjects(t *testing.T) {
 		}
 	}
 }
+
+func Test_DAOVariableOrder(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	for _, name := range []string{"zeta", "alpha", "mu", "beta"} {
+		require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: name, Type: sdk.SecretVariable, Value: name}, u))
+	}
+	expected := []string{"alpha", "beta", "mu", "zeta"}
+
+	checkOrder := func(vs []sdk.ApplicationVariable) {
+		require.Len(t, vs, len(expected))
+		for i := range expected {
+			require.Equal(t, expected[i], vs[i].Name)
+		}
+	}
+
+	for _, opt := range []application.LoadOptionFunc{application.LoadOptions.WithVariables, application.LoadOptions.WithVariablesWithClearPassword} {
+		res, err := application.LoadByID(db, app.ID, opt)
+		require.NoError(t, err)
+		checkOrder(res.Variables)
+	}
+
+	vsByApp, err := application.LoadAllVariablesForAppsWithDecryption(context.TODO(), db, []int64{app.ID})
+	require.NoError(t, err)
+	checkOrder(vsByApp[app.ID])
+}
+
+func Test_DAOVariableMax(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	application.SetMaxVariables(2)
+	defer application.SetMaxVariables(0)
+
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: "var1", Type: sdk.StringVariable, Value: "value"}, u))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: "var2", Type: sdk.StringVariable, Value: "value"}, u))
+	err := application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: "var3", Type: sdk.StringVariable, Value: "value"}, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	count, err := application.LoadVariableCount(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
+
+func Test_DAOVariableSkipUndecryptable(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-app",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-broken", Type: sdk.SecretVariable, Value: "my-broken-value"},
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "my-secret-value"},
+		},
+	})
+
+	_, err := db.Exec("UPDATE application_variable SET cipher_value = $2 WHERE application_id = $1 AND var_name = 'my-broken'", app.ID, []byte("not encrypted"))
+	require.NoError(t, err)
+
+	_, err = application.LoadByID(db, app.ID, application.LoadOptions.WithVariablesWithClearPassword)
+	require.Error(t, err)
+	require.Equal(t, gorpmapper.ErrDecryption, sdk.Cause(err))
+
+	res, err := application.LoadByID(db, app.ID, application.LoadOptions.WithVariablesWithClearPasswordSkipUndecryptable)
+	require.NoError(t, err)
+	require.Len(t, res.Variables, 2)
+	assert.Equal(t, "my-broken", res.Variables[0].Name)
+	assert.True(t, res.Variables[0].Undecryptable)
+	assert.Empty(t, res.Variables[0].Value)
+	assert.Equal(t, "my-secret", res.Variables[1].Name)
+	assert.False(t, res.Variables[1].Undecryptable)
+	assert.Equal(t, "my-secret-value", res.Variables[1].Value)
+}
+
+func Test_DAOVariableNameCaseInsensitive(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	v1 := sdk.ApplicationVariable{Name: "my-var", Type: sdk.StringVariable, Value: "value"}
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &v1, u))
+	err := application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: "My-Var", Type: sdk.StringVariable, Value: "value"}, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrVariableExists))
+
+	v2 := sdk.ApplicationVariable{Name: "other", Type: sdk.StringVariable, Value: "value"}
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &v2, u))
+	v2.Name = "MY-VAR"
+	require.True(t, sdk.ErrorIs(application.UpdateVariable(context.TODO(), db, app.ID, &v2, nil, nil), sdk.ErrVariableExists))
+
+	// A variable can change the case of its own name
+	v1.Name = "MY-VAR"
+	require.NoError(t, application.UpdateVariable(context.TODO(), db, app.ID, &v1, nil, nil))
+
+	err = application.ImportVariables(context.TODO(), db, app.ID, []sdk.Variable{{Name: "Other", Type: sdk.StringVariable, Value: "value"}}, application.ImportModeSkip, u)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrVariableExists))
+}
+
+func Test_FindDuplicateVariables(t *testing.T) {
+	db, cache := test.SetupPG(t)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name: "my-app",
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-var", Type: sdk.StringVariable, Value: "value"},
+			{Name: "other", Type: sdk.StringVariable, Value: "value"},
+		},
+	})
+
+	res, err := application.FindDuplicateVariables(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	// Duplicates can only exist from before the unique index, drop it in a transaction that is rolled back
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback() // nolint
+	_, err = tx.Exec("DROP INDEX IF EXISTS idx_application_variable_lower_name")
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO application_variable (application_id, var_name, var_type) VALUES ($1, 'My-Var', 'string')", app.ID)
+	require.NoError(t, err)
+
+	res, err = application.FindDuplicateVariables(context.TODO(), tx, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, app.ID, res[0].ApplicationID)
+	require.Equal(t, "my-app", res[0].ApplicationName)
+	require.ElementsMatch(t, []string{"My-Var", "my-var"}, res[0].Names)
+}
@@ -0,0 +1,146 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+type dbApplicationWebhook struct {
+	gorpmapper.SignedEntity
+	sdk.ApplicationWebhook
+}
+
+func (e dbApplicationWebhook) Canonical() gorpmapper.CanonicalForms {
+	var _ = []interface{}{e.ApplicationID, e.ID, e.URL}
+	return gorpmapper.CanonicalForms{
+		"{{print .ApplicationID}}{{print .ID}}{{.URL}}",
+	}
+}
+
+// LoadWebhooks returns the webhooks of an application ordered by url.
+// Signing keys are replaced by a placeholder if !withClearSigningKey.
+func LoadWebhooks(db gorp.SqlExecutor, appID int64, withClearSigningKey bool) ([]sdk.ApplicationWebhook, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT *
+	FROM application_webhook
+	WHERE application_id = $1
+	ORDER BY url ASC`).Args(appID)
+
+	var res []dbApplicationWebhook
+	if err := gorpmapping.GetAll(context.Background(), db, query, &res, gorpmapping.GetOptions.WithDecryption); err != nil {
+		return nil, sdk.WrapError(err, "unable to load webhooks")
+	}
+
+	whs := make([]sdk.ApplicationWebhook, 0, len(res))
+	for i := range res {
+		isValid, err := gorpmapping.CheckSignature(res[i], res[i].Signature)
+		if err != nil {
+			return nil, err
+		}
+		if !isValid {
+			log.Error(context.Background(), "application.LoadWebhooks> application_webhook %d data corrupted", res[i].ID)
+			continue
+		}
+		wh := res[i].ApplicationWebhook
+		if !withClearSigningKey && wh.SigningKey != "" {
+			wh.SigningKey = sdk.PasswordPlaceholder
+		}
+		whs = append(whs, wh)
+	}
+	return whs, nil
+}
+
+func loadWebhook(db gorp.SqlExecutor, appID, id int64) (*dbApplicationWebhook, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT *
+	FROM application_webhook
+	WHERE application_id = $1 AND id = $2`).Args(appID, id)
+	var wh dbApplicationWebhook
+	found, err := gorpmapping.Get(context.Background(), db, query, &wh, gorpmapping.GetOptions.WithDecryption)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+	isValid, err := gorpmapping.CheckSignature(wh, wh.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		log.Error(context.Background(), "application.loadWebhook> application_webhook %d data corrupted", wh.ID)
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+	return &wh, nil
+}
+
+// InsertWebhook adds a webhook to an application, its url should be unique in the application.
+func InsertWebhook(ctx context.Context, db gorpmapper.SqlExecutorWithTx, wh *sdk.ApplicationWebhook) error {
+	if err := wh.IsValid(); err != nil {
+		return err
+	}
+	if err := CheckWritable(ctx, db, wh.ApplicationID); err != nil {
+		return err
+	}
+	wh.Created = time.Now()
+	dbWh := dbApplicationWebhook{ApplicationWebhook: *wh}
+	if err := gorpmapping.InsertAndSign(ctx, db, &dbWh); err != nil {
+		if e, ok := sdk.Cause(err).(*pq.Error); ok && e.Code == gorpmapper.ViolateUniqueKeyPGCode {
+			return sdk.NewErrorFrom(sdk.ErrAlreadyExist, "webhook %s already exists", wh.URL)
+		}
+		return sdk.WrapError(err, "cannot insert webhook %s", wh.URL)
+	}
+	*wh = dbWh.ApplicationWebhook
+	return nil
+}
+
+// UpdateWebhook updates a webhook of an application. A placeholder signing key keeps the existing one.
+func UpdateWebhook(ctx context.Context, db gorpmapper.SqlExecutorWithTx, wh *sdk.ApplicationWebhook) error {
+	if err := wh.IsValid(); err != nil {
+		return err
+	}
+	if err := CheckWritable(ctx, db, wh.ApplicationID); err != nil {
+		return err
+	}
+	old, err := loadWebhook(db, wh.ApplicationID, wh.ID)
+	if err != nil {
+		return err
+	}
+	if wh.SigningKey == sdk.PasswordPlaceholder {
+		wh.SigningKey = old.SigningKey
+	}
+	wh.Created = old.Created
+	dbWh := dbApplicationWebhook{ApplicationWebhook: *wh}
+	if err := gorpmapping.UpdateAndSign(ctx, db, &dbWh); err != nil {
+		return sdk.WrapError(err, "cannot update webhook %d", wh.ID)
+	}
+	*wh = dbWh.ApplicationWebhook
+	return nil
+}
+
+// DeleteWebhook removes a webhook from an application.
+func DeleteWebhook(ctx context.Context, db gorp.SqlExecutor, appID, id int64) error {
+	if err := CheckWritable(ctx, db, appID); err != nil {
+		return err
+	}
+	res, err := db.Exec("DELETE FROM application_webhook WHERE application_id = $1 AND id = $2", appID, id)
+	if err != nil {
+		return sdk.WrapError(err, "cannot delete webhook %d", id)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	if n == 0 {
+		return sdk.WithStack(sdk.ErrNotFound)
+	}
+	return nil
+}
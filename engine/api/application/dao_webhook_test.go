@@ -0,0 +1,59 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestWebhooks(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	require.Error(t, application.InsertWebhook(context.TODO(), db, &sdk.ApplicationWebhook{ApplicationID: app.ID, URL: "ftp://my-hook", Events: []string{sdk.ApplicationChangeInsert}}))
+	require.Error(t, application.InsertWebhook(context.TODO(), db, &sdk.ApplicationWebhook{ApplicationID: app.ID, URL: "https://my-hook", Events: []string{"unknown"}}))
+
+	wh := sdk.ApplicationWebhook{
+		ApplicationID: app.ID,
+		URL:           "https://my-hook/notify",
+		Events:        []string{sdk.ApplicationChangeInsert, sdk.ApplicationChangeUpdate},
+		SigningKey:    "my-signing-key",
+	}
+	require.NoError(t, application.InsertWebhook(context.TODO(), db, &wh))
+	require.NotZero(t, wh.ID)
+
+	err := application.InsertWebhook(context.TODO(), db, &sdk.ApplicationWebhook{ApplicationID: app.ID, URL: wh.URL, Events: []string{sdk.ApplicationChangeInsert}})
+	require.True(t, sdk.ErrorIs(err, sdk.ErrAlreadyExist))
+
+	res, err := application.LoadByID(db, app.ID, application.LoadOptions.WithWebhooks)
+	require.NoError(t, err)
+	require.Len(t, res.Webhooks, 1)
+	require.Equal(t, sdk.PasswordPlaceholder, res.Webhooks[0].SigningKey)
+	require.Equal(t, sdk.StringSlice{sdk.ApplicationChangeInsert, sdk.ApplicationChangeUpdate}, res.Webhooks[0].Events)
+
+	// Placeholder keeps the signing key
+	upd := res.Webhooks[0]
+	upd.Events = []string{sdk.ApplicationChangeDelete}
+	require.NoError(t, application.UpdateWebhook(context.TODO(), db, &upd))
+	whs, err := application.LoadWebhooks(db, app.ID, true)
+	require.NoError(t, err)
+	require.Len(t, whs, 1)
+	require.Equal(t, "my-signing-key", whs[0].SigningKey)
+	require.Equal(t, sdk.StringSlice{sdk.ApplicationChangeDelete}, whs[0].Events)
+
+	require.NoError(t, application.DeleteWebhook(context.TODO(), db, app.ID, wh.ID))
+	require.True(t, sdk.ErrorIs(application.DeleteWebhook(context.TODO(), db, app.ID, wh.ID), sdk.ErrNotFound))
+	whs, err = application.LoadWebhooks(db, app.ID, false)
+	require.NoError(t, err)
+	require.Len(t, whs, 0)
+}
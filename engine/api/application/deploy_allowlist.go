@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// SetDeployAllowlist sets the environment and integration names that workflows can deploy given application to.
+// An empty list removes the restriction for its kind of target. The lists are not part of the signed data, the
+// change is recorded in the changelog.
+func SetDeployAllowlist(db gorp.SqlExecutor, appID int64, allowlist sdk.ApplicationDeployAllowlist) error {
+	envs, err := deployAllowlistNames(appID, allowlist.Environments)
+	if err != nil {
+		return err
+	}
+	integrations, err := deployAllowlistNames(appID, allowlist.Integrations)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec("UPDATE application SET deploy_allowlist_environments = $2, deploy_allowlist_integrations = $3 WHERE id = $1",
+		appID, envs, integrations)
+	if err != nil {
+		return sdk.WrapError(err, "cannot set deploy allowlist for application %d", appID)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	if n == 0 {
+		return sdk.WithStack(sdk.ErrNotFound)
+	}
+	return insertUpdateChanges(context.Background(), db, []int64{appID})
+}
+
+// deployAllowlistNames returns given names sorted and without duplicates, nil if there is none.
+func deployAllowlistNames(appID int64, names []string) (pq.StringArray, error) {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "deploy allowlist of application %d should not contain empty names", appID)
+		}
+		set[name] = struct{}{}
+	}
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return sortedNames(set), nil
+}
+
+// LoadDeployAllowlist returns the sorted environment and integration names that given application can be deployed
+// to, an empty list means no restriction for its kind of target.
+func LoadDeployAllowlist(db gorp.SqlExecutor, appID int64) (sdk.ApplicationDeployAllowlist, error) {
+	var envs, integrations pq.StringArray
+	if err := db.QueryRow("SELECT deploy_allowlist_environments, deploy_allowlist_integrations FROM application WHERE id = $1", appID).
+		Scan(&envs, &integrations); err != nil {
+		if err == sql.ErrNoRows {
+			return sdk.ApplicationDeployAllowlist{}, sdk.WithStack(sdk.ErrNotFound)
+		}
+		return sdk.ApplicationDeployAllowlist{}, sdk.WrapError(err, "cannot load deploy allowlist for application %d", appID)
+	}
+	return sdk.ApplicationDeployAllowlist{Environments: envs, Integrations: integrations}, nil
+}
+
+// CheckDeployTarget returns sdk.ErrForbidden if given environment or integration name is not in the matching deploy
+// allowlist of the application. Empty names are ignored. It is checked before starting a node run.
+func CheckDeployTarget(db gorp.SqlExecutor, appID int64, environment, integration string) error {
+	allowlist, err := LoadDeployAllowlist(db, appID)
+	if err != nil {
+		return err
+	}
+	if environment != "" && len(allowlist.Environments) > 0 && !sdk.IsInArray(environment, allowlist.Environments) {
+		return sdk.NewErrorFrom(sdk.ErrForbidden, "application %d is not allowed to deploy to environment %s", appID, environment)
+	}
+	if integration != "" && len(allowlist.Integrations) > 0 && !sdk.IsInArray(integration, allowlist.Integrations) {
+		return sdk.NewErrorFrom(sdk.ErrForbidden, "application %d is not allowed to deploy to integration %s", appID, integration)
+	}
+	return nil
+}
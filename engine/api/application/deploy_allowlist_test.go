@@ -0,0 +1,61 @@
+package application_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestDeployAllowlist(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	// Without allowlist all targets are allowed
+	allowlist, err := application.LoadDeployAllowlist(db, app.ID)
+	require.NoError(t, err)
+	require.Empty(t, allowlist.Environments)
+	require.Empty(t, allowlist.Integrations)
+	require.NoError(t, application.CheckDeployTarget(db, app.ID, "production", "my-integration"))
+
+	require.NoError(t, application.SetDeployAllowlist(db, app.ID, sdk.ApplicationDeployAllowlist{
+		Environments: []string{"staging", "my-env", "staging"},
+	}))
+	allowlist, err = application.LoadDeployAllowlist(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"my-env", "staging"}, allowlist.Environments)
+	require.Empty(t, allowlist.Integrations)
+
+	// An environment only list doesn't restrict integrations
+	require.NoError(t, application.CheckDeployTarget(db, app.ID, "staging", "my-integration"))
+	require.NoError(t, application.CheckDeployTarget(db, app.ID, "", "my-integration"))
+	err = application.CheckDeployTarget(db, app.ID, "production", "my-integration")
+	require.True(t, sdk.ErrorIs(err, sdk.ErrForbidden))
+
+	// An integration can't be allowed by an environment with the same name
+	require.NoError(t, application.SetDeployAllowlist(db, app.ID, sdk.ApplicationDeployAllowlist{
+		Environments: []string{"staging"},
+		Integrations: []string{"my-integration"},
+	}))
+	require.NoError(t, application.CheckDeployTarget(db, app.ID, "staging", "my-integration"))
+	err = application.CheckDeployTarget(db, app.ID, "", "staging")
+	require.True(t, sdk.ErrorIs(err, sdk.ErrForbidden))
+	require.NoError(t, application.CheckDeployTarget(db, app.ID, "", ""))
+
+	require.Error(t, application.SetDeployAllowlist(db, app.ID, sdk.ApplicationDeployAllowlist{Integrations: []string{" "}}))
+
+	require.NoError(t, application.SetDeployAllowlist(db, app.ID, sdk.ApplicationDeployAllowlist{}))
+	require.NoError(t, application.CheckDeployTarget(db, app.ID, "production", "other-integration"))
+
+	require.True(t, sdk.ErrorIs(application.SetDeployAllowlist(db, 0, sdk.ApplicationDeployAllowlist{}), sdk.ErrNotFound))
+	_, err = application.LoadDeployAllowlist(db, 0)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
@@ -0,0 +1,167 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// ApplicationDiff lists the fields that differ between two applications with the same name.
+type ApplicationDiff struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+}
+
+// ProjectsDiff is the comparison of the applications of two projects, applications are identified by name.
+type ProjectsDiff struct {
+	OnlyInA []string          `json:"only_in_a"`
+	OnlyInB []string          `json:"only_in_b"`
+	Changed []ApplicationDiff `json:"changed"`
+}
+
+// Diff returns the json names of the fields that differ between given applications, variables and keys are reported
+// by name as variables.<name> and keys.<name>. Secrets are only compared by presence so they should be loaded
+// without decryption. Identifiers, project and dates are not compared.
+func Diff(a, b sdk.Application) []string {
+	var fields []string
+	add := func(field string, equal bool) {
+		if !equal {
+			fields = append(fields, field)
+		}
+	}
+	add("description", a.Description == b.Description)
+	add("vcs_server", a.VCSServer == b.VCSServer)
+	add("repository_fullname", a.RepositoryFullname == b.RepositoryFullname)
+	sa, sb := a.RepositoryStrategy, b.RepositoryStrategy
+	add("vcs_strategy.connection_type", sa.ConnectionType == sb.ConnectionType)
+	add("vcs_strategy.ssh_key", sa.SSHKey == sb.SSHKey)
+	add("vcs_strategy.user", sa.User == sb.User)
+	add("vcs_strategy.password", (sa.Password == "") == (sb.Password == ""))
+	add("vcs_strategy.branch", sa.Branch == sb.Branch)
+	add("vcs_strategy.default_branch", sa.DefaultBranch == sb.DefaultBranch)
+	add("vcs_strategy.pgp_key", sa.PGPKey == sb.PGPKey)
+	add("metadata", len(a.Metadata) == 0 && len(b.Metadata) == 0 || reflect.DeepEqual(a.Metadata, b.Metadata))
+	add("from_repository", a.FromRepository == b.FromRepository)
+	add("read_only", a.ReadOnly == b.ReadOnly)
+	add("frozen", a.Frozen == b.Frozen)
+	add("retention_days", a.RetentionDays == b.RetentionDays)
+	add("max_concurrent_runs", a.MaxConcurrentRuns == b.MaxConcurrentRuns)
+	add("color", a.Color == b.Color)
+
+	varsA := make(map[string]sdk.ApplicationVariable, len(a.Variables))
+	for _, v := range a.Variables {
+		varsA[v.Name] = v
+	}
+	varsB := make(map[string]sdk.ApplicationVariable, len(b.Variables))
+	for _, v := range b.Variables {
+		varsB[v.Name] = v
+	}
+	names := make(map[string]struct{}, len(varsA)+len(varsB))
+	for name := range varsA {
+		names[name] = struct{}{}
+	}
+	for name := range varsB {
+		names[name] = struct{}{}
+	}
+	for _, name := range sortedNames(names) {
+		va, okA := varsA[name]
+		vb, okB := varsB[name]
+		equal := okA && okB && va.Type == vb.Type && (sdk.NeedPlaceholder(va.Type) || va.Value == vb.Value)
+		add("variables."+name, equal)
+	}
+
+	keysA := make(map[string]sdk.ApplicationKey, len(a.Keys))
+	for _, k := range a.Keys {
+		keysA[k.Name] = k
+	}
+	keysB := make(map[string]sdk.ApplicationKey, len(b.Keys))
+	for _, k := range b.Keys {
+		keysB[k.Name] = k
+	}
+	names = make(map[string]struct{}, len(keysA)+len(keysB))
+	for name := range keysA {
+		names[name] = struct{}{}
+	}
+	for name := range keysB {
+		names[name] = struct{}{}
+	}
+	for _, name := range sortedNames(names) {
+		ka, okA := keysA[name]
+		kb, okB := keysB[name]
+		add("keys."+name, okA && okB && ka.Type == kb.Type)
+	}
+
+	return fields
+}
+
+func sortedNames(names map[string]struct{}) []string {
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// DiffProjects compares the applications of two projects by name. Nothing is written.
+func DiffProjects(ctx context.Context, db gorp.SqlExecutor, projectA, projectB int64) (*ProjectsDiff, error) {
+	appsA, err := loadAllForDiff(ctx, db, projectA)
+	if err != nil {
+		return nil, err
+	}
+	appsB, err := loadAllForDiff(ctx, db, projectB)
+	if err != nil {
+		return nil, err
+	}
+
+	res := ProjectsDiff{
+		OnlyInA: []string{},
+		OnlyInB: []string{},
+		Changed: []ApplicationDiff{},
+	}
+	for name, a := range appsA {
+		b, has := appsB[name]
+		if !has {
+			res.OnlyInA = append(res.OnlyInA, name)
+			continue
+		}
+		if fields := Diff(a, b); len(fields) > 0 {
+			res.Changed = append(res.Changed, ApplicationDiff{Name: name, Fields: fields})
+		}
+	}
+	for name := range appsB {
+		if _, has := appsA[name]; !has {
+			res.OnlyInB = append(res.OnlyInB, name)
+		}
+	}
+	sort.Strings(res.OnlyInA)
+	sort.Strings(res.OnlyInB)
+	sort.Slice(res.Changed, func(i, j int) bool { return res.Changed[i].Name < res.Changed[j].Name })
+	return &res, nil
+}
+
+// loadAllForDiff loads the applications of a project with the vcs strategy password decrypted so its presence
+// can be compared, and variables without decryption.
+func loadAllForDiff(ctx context.Context, db gorp.SqlExecutor, projectID int64) (map[string]sdk.Application, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return nil, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.project_id = $1`).Args(projectID)
+	apps, err := getAllWithClearVCS(ctx, db, []LoadOptionFunc{LoadOptions.WithVariables, LoadOptions.WithKeys}, query)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]sdk.Application, len(apps))
+	for _, app := range apps {
+		res[app.Name] = app
+	}
+	return res, nil
+}
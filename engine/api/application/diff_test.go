@@ -0,0 +1,61 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestDiff(t *testing.T) {
+	a := sdk.Application{
+		Name:               "my-app",
+		Description:        "my description",
+		RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", Password: "a"},
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "a"},
+			{Name: "my-text", Type: sdk.StringVariable, Value: "a"},
+			{Name: "only-a", Type: sdk.StringVariable},
+		},
+	}
+	b := sdk.Application{
+		Name:               "my-app",
+		RepositoryStrategy: sdk.RepositoryStrategy{ConnectionType: "https", Password: "b"},
+		Metadata:           sdk.Metadata{},
+		Variables: []sdk.ApplicationVariable{
+			{Name: "my-secret", Type: sdk.SecretVariable, Value: "b"},
+			{Name: "my-text", Type: sdk.StringVariable, Value: "b"},
+		},
+	}
+	require.Equal(t, []string{"description", "variables.my-text", "variables.only-a"}, application.Diff(a, b))
+
+	b.RepositoryStrategy.Password = ""
+	require.Contains(t, application.Diff(a, b), "vcs_strategy.password")
+	require.Empty(t, application.Diff(a, a))
+}
+
+func TestDiffProjects(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	keyA, keyB := sdk.RandomString(10), sdk.RandomString(10)
+	projA := assets.InsertTestProject(t, db, cache, keyA, keyA)
+	projB := assets.InsertTestProject(t, db, cache, keyB, keyB)
+	assets.InsertTestApplication(t, db, projA, sdk.Application{Name: "my-app-a"})
+	assets.InsertTestApplication(t, db, projB, sdk.Application{Name: "my-app-b"})
+	assets.InsertTestApplication(t, db, projA, sdk.Application{Name: "my-app", Description: "a"})
+	assets.InsertTestApplication(t, db, projB, sdk.Application{Name: "my-app", Description: "b"})
+	assets.InsertTestApplication(t, db, projA, sdk.Application{Name: "my-same-app"})
+	assets.InsertTestApplication(t, db, projB, sdk.Application{Name: "my-same-app"})
+
+	res, err := application.DiffProjects(context.TODO(), db, projA.ID, projB.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"my-app-a"}, res.OnlyInA)
+	require.Equal(t, []string{"my-app-b"}, res.OnlyInB)
+	require.Equal(t, []application.ApplicationDiff{{Name: "my-app", Fields: []string{"description"}}}, res.Changed)
+}
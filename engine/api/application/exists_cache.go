@@ -0,0 +1,100 @@
+package application
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	gocache "github.com/patrickmn/go-cache"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// existsCache is an optional in memory cache for Exists results, disabled by default.
+var existsCache = struct {
+	sync.RWMutex
+	cache      *gocache.Cache
+	maxEntries int
+}{}
+
+// EnableExistsCache enables caching of Exists results for given ttl, the cache will never hold more than maxEntries results.
+func EnableExistsCache(ttl time.Duration, maxEntries int) {
+	existsCache.Lock()
+	defer existsCache.Unlock()
+	existsCache.cache = gocache.New(ttl, 2*ttl)
+	existsCache.maxEntries = maxEntries
+}
+
+// DisableExistsCache disables caching of Exists results and drops all cached values.
+func DisableExistsCache() {
+	existsCache.Lock()
+	defer existsCache.Unlock()
+	existsCache.cache = nil
+}
+
+func existsCacheKey(projectKey, appName string) string {
+	return projectKey + "/" + appName
+}
+
+func getExistsCache(projectKey, appName string) (bool, bool) {
+	existsCache.RLock()
+	defer existsCache.RUnlock()
+	if existsCache.cache == nil {
+		return false, false
+	}
+	v, has := existsCache.cache.Get(existsCacheKey(projectKey, appName))
+	if !has {
+		return false, false
+	}
+	return v.(bool), true
+}
+
+func setExistsCache(projectKey, appName string, exists bool) {
+	existsCache.RLock()
+	defer existsCache.RUnlock()
+	if existsCache.cache == nil {
+		return
+	}
+	if existsCache.cache.ItemCount() >= existsCache.maxEntries {
+		existsCache.cache.DeleteExpired()
+		if existsCache.cache.ItemCount() >= existsCache.maxEntries {
+			return
+		}
+	}
+	existsCache.cache.SetDefault(existsCacheKey(projectKey, appName), exists)
+}
+
+func isExistsCacheEnabled() bool {
+	existsCache.RLock()
+	defer existsCache.RUnlock()
+	return existsCache.cache != nil
+}
+
+// invalidateExistsCache removes all cached results for given project.
+func invalidateExistsCache(projectKey string) {
+	existsCache.RLock()
+	defer existsCache.RUnlock()
+	if existsCache.cache == nil {
+		return
+	}
+	prefix := existsCacheKey(projectKey, "")
+	for k := range existsCache.cache.Items() {
+		if strings.HasPrefix(k, prefix) {
+			existsCache.cache.Delete(k)
+		}
+	}
+}
+
+// invalidateExistsCacheByApplicationID removes all cached results for the project of given application.
+func invalidateExistsCacheByApplicationID(db gorp.SqlExecutor, appID int64) error {
+	if !isExistsCacheEnabled() {
+		return nil
+	}
+	projectKey, err := db.SelectStr("SELECT project.projectkey FROM project JOIN application ON application.project_id = project.id WHERE application.id = $1", appID)
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	invalidateExistsCache(projectKey)
+	return nil
+}
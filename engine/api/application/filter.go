@@ -0,0 +1,167 @@
+package application
+
+import (
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+// ApplicationFilter struct for application query, all fields are optional.
+type ApplicationFilter struct {
+	// NamePattern is a SQL LIKE pattern matched against application name (ex: "api-%").
+	NamePattern string
+	// Repository is the repository fullname of the application.
+	Repository string
+	// Source filters applications created from a repository or manually.
+	Source  SourceFilter
+	OrderBy FilterOrderBy
+	// OrderDesc reverses the order.
+	OrderDesc bool
+	// Limit enables pagination if greater than zero.
+	Limit  int64
+	Offset int64
+}
+
+// IsValid returns an error if the filter is not valid.
+func (f ApplicationFilter) IsValid() error {
+	if f.OrderBy != "" {
+		if err := f.OrderBy.IsValid(); err != nil {
+			return err
+		}
+	}
+	if f.Source != "" {
+		if err := f.Source.IsValid(); err != nil {
+			return err
+		}
+	}
+	if f.Limit < 0 || f.Offset < 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid given pagination")
+	}
+	return nil
+}
+
+// SQL returns the raw sql conditions for current filter.
+func (f ApplicationFilter) SQL() string {
+	var conds []string
+
+	if f.NamePattern != "" {
+		conds = append(conds, "application.name LIKE :namePattern")
+	}
+	if f.Repository != "" {
+		conds = append(conds, "application.repo_fullname = :repository")
+	}
+
+	switch f.Source {
+	case SourceAsCode:
+		conds = append(conds, "COALESCE(application.from_repository, '') <> ''")
+	case SourceManual:
+		conds = append(conds, "COALESCE(application.from_repository, '') = ''")
+	}
+
+	return gorpmapper.And(conds...)
+}
+
+// SQLOrderAndPagination returns the raw sql order and pagination clauses for current filter.
+func (f ApplicationFilter) SQLOrderAndPagination() string {
+	orderBy := f.OrderBy
+	if orderBy == "" {
+		orderBy = FilterOrderByName
+	}
+	q := "ORDER BY application." + string(orderBy)
+	if f.OrderDesc {
+		q += " DESC"
+	} else {
+		q += " ASC"
+	}
+	// Always add application id to get a stable order for pagination
+	q += ", application.id ASC"
+	if f.Limit > 0 {
+		q += " LIMIT :limit OFFSET :offset"
+	}
+	return q
+}
+
+// Args returns sql args for current filter.
+func (f ApplicationFilter) Args() gorpmapper.ArgsMap {
+	return gorpmapper.ArgsMap{
+		"namePattern": f.NamePattern,
+		"repository":  f.Repository,
+		"limit":       f.Limit,
+		"offset":      f.Offset,
+	}
+}
+
+// FilterOrderBy for application.
+type FilterOrderBy string
+
+// IsValid returns an error if the order value is not valid.
+func (o FilterOrderBy) IsValid() error {
+	switch o {
+	case FilterOrderByName, FilterOrderByLastModified:
+		return nil
+	default:
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid given order")
+	}
+}
+
+// List of const for order filter.
+const (
+	FilterOrderByName         FilterOrderBy = "name"
+	FilterOrderByLastModified FilterOrderBy = "last_modified"
+)
+
+// SourceFilter for application, an application is as code if it was created from a repository.
+// The from_repository column of manually created applications is an empty string, it is never NULL.
+type SourceFilter string
+
+// IsValid returns an error if the source value is not valid.
+func (s SourceFilter) IsValid() error {
+	switch s {
+	case SourceAsCode, SourceManual:
+		return nil
+	default:
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid given source")
+	}
+}
+
+// List of const for source filter.
+const (
+	SourceAsCode SourceFilter = "ascode"
+	SourceManual SourceFilter = "manual"
+)
+
+// Sort for application list.
+type Sort string
+
+// List of const for application list sort, a leading dash means descending order.
+const (
+	SortNameAsc          Sort = "name"
+	SortNameDesc         Sort = "-name"
+	SortLastModifiedAsc  Sort = "last_modified"
+	SortLastModifiedDesc Sort = "-last_modified"
+)
+
+// sortOrderBy only contains sorts on indexed columns.
+var sortOrderBy = map[Sort]string{
+	SortNameAsc:          "application.name ASC",
+	SortNameDesc:         "application.name DESC",
+	SortLastModifiedAsc:  "application.last_modified ASC, application.name ASC",
+	SortLastModifiedDesc: "application.last_modified DESC, application.name ASC",
+}
+
+// IsValid returns an error if the sort value is not valid.
+func (s Sort) IsValid() error {
+	if _, has := sortOrderBy[s]; !has && s != "" {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid given sort")
+	}
+	return nil
+}
+
+func (s Sort) sql() (string, error) {
+	if s == "" {
+		s = SortNameAsc
+	}
+	if err := s.IsValid(); err != nil {
+		return "", err
+	}
+	return sortOrderBy[s], nil
+}
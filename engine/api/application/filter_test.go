@@ -0,0 +1,129 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadAllWithFilter(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	for _, a := range []sdk.Application{
+		{Name: "api-1", RepositoryFullname: "ovh/api", FromRepository: "https://github.com/ovh/api.git"},
+		{Name: "api-2", RepositoryFullname: "ovh/api"},
+		{Name: "ui-1", RepositoryFullname: "ovh/ui"},
+		{Name: "worker"},
+	} {
+		app := a
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	}
+
+	names := func(apps []sdk.Application) []string {
+		res := make([]string, len(apps))
+		for i := range apps {
+			res[i] = apps[i].Name
+		}
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		filter   application.ApplicationFilter
+		expected []string
+	}{
+		{"empty", application.ApplicationFilter{}, []string{"api-1", "api-2", "ui-1", "worker"}},
+		{"name pattern", application.ApplicationFilter{NamePattern: "%-1"}, []string{"api-1", "ui-1"}},
+		{"repository", application.ApplicationFilter{Repository: "ovh/api"}, []string{"api-1", "api-2"}},
+		{"name pattern and repository", application.ApplicationFilter{NamePattern: "%-2", Repository: "ovh/api"}, []string{"api-2"}},
+		{"order desc", application.ApplicationFilter{OrderDesc: true}, []string{"worker", "ui-1", "api-2", "api-1"}},
+		{"order by last modified", application.ApplicationFilter{OrderBy: application.FilterOrderByLastModified}, []string{"api-1", "api-2", "ui-1", "worker"}},
+		{"pagination", application.ApplicationFilter{Limit: 2, Offset: 1}, []string{"api-2", "ui-1"}},
+		{"repository and pagination", application.ApplicationFilter{Repository: "ovh/api", OrderDesc: true, Limit: 1}, []string{"api-2"}},
+		{"source ascode", application.ApplicationFilter{Source: application.SourceAsCode}, []string{"api-1"}},
+		{"source manual", application.ApplicationFilter{Source: application.SourceManual}, []string{"api-2", "ui-1", "worker"}},
+		{"source and repository", application.ApplicationFilter{Source: application.SourceManual, Repository: "ovh/api"}, []string{"api-2"}},
+		{"no match", application.ApplicationFilter{NamePattern: "unknown%"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := application.LoadAllWithFilter(context.TODO(), db, proj.ID, tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, names(apps))
+		})
+	}
+
+	_, err := application.LoadAllWithFilter(context.TODO(), db, proj.ID, application.ApplicationFilter{OrderBy: "unknown"})
+	require.Error(t, err)
+	_, err = application.LoadAllWithFilter(context.TODO(), db, proj.ID, application.ApplicationFilter{Limit: -1})
+	require.Error(t, err)
+	_, err = application.LoadAllWithFilter(context.TODO(), db, proj.ID, application.ApplicationFilter{Source: "unknown"})
+	require.Error(t, err)
+
+	counts, err := application.CountBySource(db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[application.SourceFilter]int64{application.SourceAsCode: 1, application.SourceManual: 3}, counts)
+}
+
+func TestLoadAllSorted(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app2 := sdk.Application{Name: "app-2"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
+	app1 := sdk.Application{Name: "app-1"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+
+	apps, err := application.LoadAll(db, proj.Key)
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, "app-1", apps[0].Name)
+
+	apps, err = application.LoadAllSorted(db, proj.Key, application.SortNameDesc)
+	require.NoError(t, err)
+	require.Equal(t, "app-2", apps[0].Name)
+
+	apps, err = application.LoadAllSorted(db, proj.Key, application.SortLastModifiedDesc)
+	require.NoError(t, err)
+	require.Equal(t, "app-1", apps[0].Name)
+
+	_, err = application.LoadAllSorted(db, proj.Key, "name; DROP TABLE application")
+	require.Error(t, err)
+}
+
+func TestFromRepositoryIsNeverNull(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	// A row inserted without from_repository gets the empty string
+	_, err := db.Exec("INSERT INTO application (name, project_id) VALUES ($1, $2)", "my-raw-app", proj.ID)
+	require.NoError(t, err)
+	fromRepo, err := db.SelectNullStr("SELECT from_repository FROM application WHERE project_id = $1 AND name = $2", proj.ID, "my-raw-app")
+	require.NoError(t, err)
+	require.True(t, fromRepo.Valid)
+	require.Equal(t, "", fromRepo.String)
+
+	_, err = db.Exec("UPDATE application SET from_repository = NULL WHERE id = $1", app.ID)
+	require.Error(t, err)
+
+	counts, err := application.CountBySource(db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), counts[application.SourceManual])
+}
@@ -0,0 +1,27 @@
+package application
+
+import (
+	"github.com/go-gorp/gorp"
+)
+
+// Freeze prevents workflows from running nodes with given application, the application can still be updated.
+// The flag is not part of the signed data.
+func Freeze(db gorp.SqlExecutor, appID int64) error {
+	return setFrozen(db, appID, true)
+}
+
+// Unfreeze allows workflows to run nodes with given application again.
+func Unfreeze(db gorp.SqlExecutor, appID int64) error {
+	return setFrozen(db, appID, false)
+}
+
+func setFrozen(db gorp.SqlExecutor, appID int64, frozen bool) error {
+	return setColumn(db, appID, columnFrozen, frozen)
+}
+
+// LoadFrozen returns true if given application is frozen. It is checked before starting a node run.
+func LoadFrozen(db gorp.SqlExecutor, appID int64) (bool, error) {
+	var frozen bool
+	err := loadColumn(db, appID, columnFrozen, &frozen)
+	return frozen, err
+}
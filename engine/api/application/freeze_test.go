@@ -0,0 +1,41 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestFreeze(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	require.NoError(t, application.Freeze(db, app.ID))
+	frozen, err := application.LoadFrozen(db, app.ID)
+	require.NoError(t, err)
+	require.True(t, frozen)
+
+	// A frozen application can still be updated
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	res.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+
+	require.NoError(t, application.Unfreeze(db, app.ID))
+	frozen, err = application.LoadFrozen(db, app.ID)
+	require.NoError(t, err)
+	require.False(t, frozen)
+
+	_, err = application.LoadFrozen(db, 0)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
@@ -21,6 +21,8 @@ func init() {
 	gorpmapping.Register(gorpmapping.New(dbApplicationVulnerability{}, "application_vulnerability", true, "id"))
 	gorpmapping.Register(gorpmapping.New(dbApplicationVariable{}, "application_variable", true, "id"))
 	gorpmapping.Register(gorpmapping.New(dbApplicationDeploymentStrategy{}, "application_deployment_strategy", true, "id"))
+	gorpmapping.Register(gorpmapping.New(dbApplicationWebhook{}, "application_webhook", true, "id"))
+	gorpmapping.Register(gorpmapping.New(dbApplicationChange{}, "application_changelog", true, "seq"))
 }
 
 // PostGet is a db hook
@@ -0,0 +1,90 @@
+package application
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	gocache "github.com/patrickmn/go-cache"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// idCache is an optional in memory cache for LoadByID results without load options, disabled by default.
+// Each entry holds the row version (xmin) it was loaded at, so a cached application is returned only if its row
+// was not written since.
+var idCache = struct {
+	sync.RWMutex
+	cache      *gocache.Cache
+	maxEntries int
+}{}
+
+type idCacheEntry struct {
+	version string
+	app     sdk.Application
+}
+
+// EnableIDCache enables caching of LoadByID results for given ttl, the cache will never hold more than maxEntries
+// applications. Cached applications are masked like any LoadByID result.
+func EnableIDCache(ttl time.Duration, maxEntries int) {
+	idCache.Lock()
+	defer idCache.Unlock()
+	idCache.cache = gocache.New(ttl, 2*ttl)
+	idCache.maxEntries = maxEntries
+}
+
+// DisableIDCache disables caching of LoadByID results and drops all cached applications.
+func DisableIDCache() {
+	idCache.Lock()
+	defer idCache.Unlock()
+	idCache.cache = nil
+}
+
+func isIDCacheEnabled() bool {
+	idCache.RLock()
+	defer idCache.RUnlock()
+	return idCache.cache != nil
+}
+
+func getIDCache(id int64, version string) (*sdk.Application, bool) {
+	idCache.RLock()
+	defer idCache.RUnlock()
+	if idCache.cache == nil {
+		return nil, false
+	}
+	v, has := idCache.cache.Get(strconv.FormatInt(id, 10))
+	if !has {
+		return nil, false
+	}
+	e := v.(idCacheEntry)
+	if e.version != version {
+		return nil, false
+	}
+	app := copyApplication(e.app)
+	return &app, true
+}
+
+func setIDCache(version string, app sdk.Application) {
+	idCache.RLock()
+	defer idCache.RUnlock()
+	if idCache.cache == nil {
+		return
+	}
+	if idCache.cache.ItemCount() >= idCache.maxEntries {
+		idCache.cache.DeleteExpired()
+		if idCache.cache.ItemCount() >= idCache.maxEntries {
+			return
+		}
+	}
+	idCache.cache.SetDefault(strconv.FormatInt(app.ID, 10), idCacheEntry{version: version, app: copyApplication(app)})
+}
+
+// loadRowVersion returns the version of the row of given application, it is empty if the application doesn't exist.
+func loadRowVersion(db gorp.SqlExecutor, id int64) (string, error) {
+	version, err := db.SelectNullStr("SELECT xmin::text FROM application WHERE id = $1", id)
+	if err != nil {
+		return "", sdk.WrapError(err, "cannot load row version of application %d", id)
+	}
+	return version.String, nil
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+const idempotencyKeyMaxLength = 256
+
+// idempotencyKeyTTL is the validity of idempotency keys as a time.Duration.
+var idempotencyKeyTTL = int64(24 * time.Hour)
+
+// SetIdempotencyKeyTTL sets the duration during which an idempotency key returns the application it created.
+func SetIdempotencyKeyTTL(ttl time.Duration) {
+	atomic.StoreInt64(&idempotencyKeyTTL, int64(ttl))
+}
+
+// ContextWithIdempotencyKey returns a copy of the context that holds an idempotency key for Insert.
+// Replaying an insert with the same key for the same project returns the application created by the first call.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, contextIdempotencyKey, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key set with ContextWithIdempotencyKey.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(contextIdempotencyKey).(string)
+	return key
+}
+
+// LoadByIdempotencyKey returns the application created with given idempotency key for given project.
+// It returns sdk.ErrNotFound if the key was not used or has expired.
+func LoadByIdempotencyKey(db gorp.SqlExecutor, projectID int64, key string, opts ...LoadOptionFunc) (*sdk.Application, error) {
+	ttl := time.Duration(atomic.LoadInt64(&idempotencyKeyTTL))
+	appID, err := db.SelectInt(`
+	SELECT application_id
+	FROM application_idempotency_key
+	WHERE project_id = $1 AND idem_key = $2 AND created > $3`, projectID, key, time.Now().Add(-ttl))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, sdk.WrapError(err, "cannot load idempotency key for project %d", projectID)
+	}
+	if appID == 0 {
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+	return LoadByID(db, appID, opts...)
+}
+
+// insertIdempotencyKey records the application created with given key, an expired record for the same key is replaced.
+func insertIdempotencyKey(db gorp.SqlExecutor, projectID int64, key string, appID int64) error {
+	if _, err := db.Exec(`
+	INSERT INTO application_idempotency_key (project_id, idem_key, application_id, created)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (project_id, idem_key) DO UPDATE SET application_id = $3, created = $4`, projectID, key, appID, time.Now()); err != nil {
+		return sdk.WrapError(err, "cannot insert idempotency key for application %d", appID)
+	}
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes the idempotency keys that have expired and returns how many were deleted.
+func PurgeExpiredIdempotencyKeys(db gorp.SqlExecutor) (int64, error) {
+	ttl := time.Duration(atomic.LoadInt64(&idempotencyKeyTTL))
+	res, err := db.Exec("DELETE FROM application_idempotency_key WHERE created <= $1", time.Now().Add(-ttl))
+	if err != nil {
+		return 0, sdk.WrapError(err, "cannot purge expired idempotency keys")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, sdk.WithStack(err)
+	}
+	return n, nil
+}
+
+// PurgeIdempotencyKeys deletes expired idempotency keys each purge interval, until given context is done.
+func PurgeIdempotencyKeys(ctx context.Context, db gorp.SqlExecutor, purgeInterval time.Duration) {
+	tick := time.NewTicker(purgeInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if _, err := PurgeExpiredIdempotencyKeys(db); err != nil {
+				log.Error(ctx, "application.PurgeIdempotencyKeys> %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestInsertWithIdempotencyKey(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	ctx := application.ContextWithIdempotencyKey(context.TODO(), sdk.RandomString(10))
+
+	app1 := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(ctx, db, *proj, &app1))
+
+	// Replaying the creation returns the existing application instead of a conflict
+	app2 := sdk.Application{Name: "my-app"}
+	replay, err := application.InsertWithResult(ctx, db, *proj, &app2)
+	require.NoError(t, err)
+	require.True(t, replay.Replayed)
+	require.Equal(t, app1.ID, replay.ID)
+	require.Equal(t, app1.ID, app2.ID)
+
+	res, err := application.LoadByIdempotencyKey(db, proj.ID, application.IdempotencyKeyFromContext(ctx))
+	require.NoError(t, err)
+	require.Equal(t, app1.ID, res.ID)
+
+	// Expired keys are ignored
+	application.SetIdempotencyKeyTTL(0)
+	defer application.SetIdempotencyKeyTTL(24 * time.Hour)
+	_, err = application.LoadByIdempotencyKey(db, proj.ID, application.IdempotencyKeyFromContext(ctx))
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+	app3 := sdk.Application{Name: "my-app"}
+	require.Error(t, application.Insert(ctx, db, *proj, &app3))
+
+	n, err := application.PurgeExpiredIdempotencyKeys(db)
+	require.NoError(t, err)
+	require.True(t, n >= 1)
+	application.SetIdempotencyKeyTTL(24 * time.Hour)
+	_, err = application.LoadByIdempotencyKey(db, proj.ID, application.IdempotencyKeyFromContext(ctx))
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
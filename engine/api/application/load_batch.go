@@ -0,0 +1,57 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// batchLoadFunc loads the data of an option for all given applications at once.
+type batchLoadFunc func(db gorp.SqlExecutor, apps []*sdk.Application) error
+
+// batchLoadOptions are the options that load their data with one query for a list of applications instead of a
+// query by application. When a single application is loaded the option itself is used.
+var batchLoadOptions = map[LoadOptionFunc]batchLoadFunc{
+	&loadPipelines: loadPipelinesBatch,
+}
+
+// splitBatchLoadOptions removes from given options the ones that have a batch loader.
+func splitBatchLoadOptions(opts []LoadOptionFunc) ([]LoadOptionFunc, []batchLoadFunc) {
+	var others []LoadOptionFunc
+	var batch []batchLoadFunc
+	for _, f := range opts {
+		if b, ok := batchLoadOptions[f]; ok {
+			batch = append(batch, b)
+			continue
+		}
+		others = append(others, f)
+	}
+	return others, batch
+}
+
+// applyBatchLoadOptions runs each given batch loader on all the applications.
+func applyBatchLoadOptions(ctx context.Context, db gorp.SqlExecutor, bestEffort bool, batch []batchLoadFunc, apps []sdk.Application) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	ptrs := make([]*sdk.Application, len(apps))
+	for i := range apps {
+		ptrs[i] = &apps[i]
+	}
+	if len(ptrs) == 0 {
+		return nil
+	}
+	for _, f := range batch {
+		if err := f(db, ptrs); err != nil {
+			if bestEffort {
+				log.Warning(ctx, "application.applyBatchLoadOptions> unable to load optional data: %v", err)
+				continue
+			}
+			return sdk.WrapError(err, "application.applyBatchLoadOptions")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+var loadOptionConcurrency = struct {
+	sync.RWMutex
+	values map[LoadOptionFunc]int
+}{values: make(map[LoadOptionFunc]int)}
+
+// SetLoadOptionConcurrency sets the number of applications for which given option runs at the same time when a list
+// of applications is loaded. A value lower than 2 runs the option serially, which is the default. Options always run
+// serially when applications are loaded in a transaction.
+func SetLoadOptionConcurrency(opt LoadOptionFunc, concurrency int) {
+	loadOptionConcurrency.Lock()
+	defer loadOptionConcurrency.Unlock()
+	if concurrency < 2 {
+		delete(loadOptionConcurrency.values, opt)
+		return
+	}
+	loadOptionConcurrency.values[opt] = concurrency
+}
+
+func getLoadOptionConcurrency(opt LoadOptionFunc) int {
+	loadOptionConcurrency.RLock()
+	defer loadOptionConcurrency.RUnlock()
+	return loadOptionConcurrency.values[opt]
+}
+
+func isBestEffort(opts []LoadOptionFunc) bool {
+	for _, f := range opts {
+		if f == LoadOptions.WithBestEffort {
+			return true
+		}
+	}
+	return false
+}
+
+func isExistingProject(opts []LoadOptionFunc) bool {
+	for _, f := range opts {
+		if f == LoadOptions.WithExistingProject {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLoadOptions returns the options that should run serially for each application and the ones that should
+// run concurrently on all the applications.
+func splitLoadOptions(db gorp.SqlExecutor, opts []LoadOptionFunc) ([]LoadOptionFunc, []LoadOptionFunc) {
+	if ro, ok := db.(readOnlyLoadExecutor); ok {
+		db = ro.SqlExecutor
+	}
+	if _, isTx := db.(*gorp.Transaction); isTx {
+		return opts, nil
+	}
+	var serial, concurrent []LoadOptionFunc
+	for _, f := range opts {
+		if getLoadOptionConcurrency(f) > 1 {
+			concurrent = append(concurrent, f)
+		} else {
+			serial = append(serial, f)
+		}
+	}
+	return serial, concurrent
+}
+
+// applyConcurrentLoadOptions runs each given option on all the applications with the configured concurrency.
+func applyConcurrentLoadOptions(ctx context.Context, db gorp.SqlExecutor, bestEffort bool, opts []LoadOptionFunc, apps []sdk.Application) error {
+	for _, f := range opts {
+		sem := make(chan struct{}, getLoadOptionConcurrency(f))
+		var wg sync.WaitGroup
+		var errOnce sync.Once
+		var firstErr error
+		for i := range apps {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(app *sdk.Application) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if err := (*f)(db, app); err != nil && sdk.Cause(err) != sql.ErrNoRows {
+					if bestEffort {
+						log.Warning(ctx, "application.applyConcurrentLoadOptions> unable to load optional data for application %d: %v", app.ID, err)
+						return
+					}
+					errOnce.Do(func() { firstErr = sdk.WrapError(err, "application.applyConcurrentLoadOptions") })
+				}
+			}(&apps[i])
+		}
+		wg.Wait()
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package application_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadOptionConcurrency(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	for i := 0; i < 6; i++ {
+		app := sdk.Application{Name: fmt.Sprintf("my-app%d", i)}
+		require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	}
+
+	var mutex sync.Mutex
+	var running, maxRunning int
+	slowOption := func(db gorp.SqlExecutor, app *sdk.Application) error {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		app.Description = "loaded"
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		return nil
+	}
+	opt := application.LoadOptionFunc(&slowOption)
+
+	// Serial by default
+	apps, err := application.LoadAll(db, proj.Key, opt)
+	require.NoError(t, err)
+	require.Len(t, apps, 6)
+	require.Equal(t, 1, maxRunning)
+
+	maxRunning = 0
+	application.SetLoadOptionConcurrency(opt, 3)
+	defer application.SetLoadOptionConcurrency(opt, 0)
+	apps, err = application.LoadAll(db, proj.Key, opt)
+	require.NoError(t, err)
+	require.Len(t, apps, 6)
+	require.Equal(t, 3, maxRunning)
+	for _, a := range apps {
+		require.Equal(t, "loaded", a.Description)
+	}
+}
@@ -0,0 +1,120 @@
+package application
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+type sharedLoadCall struct {
+	wg  sync.WaitGroup
+	app *sdk.Application
+	err error
+}
+
+// sharedLoads holds the pending LoadByIDShared calls by id and options.
+var sharedLoads = struct {
+	sync.Mutex
+	calls map[string]*sharedLoadCall
+}{calls: make(map[string]*sharedLoadCall)}
+
+// LoadByIDShared loads an application like LoadByID, but concurrent calls for the same id and options
+// share the same database query and signature check. Each caller gets its own copy of the application.
+// As the query is done with the db of the first caller, given db should not be a transaction.
+func LoadByIDShared(db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.Application, error) {
+	key := fmt.Sprintf("%d-%v", id, opts)
+
+	sharedLoads.Lock()
+	if c, has := sharedLoads.calls[key]; has {
+		sharedLoads.Unlock()
+		c.wg.Wait()
+		return copySharedLoadResult(c)
+	}
+	c := new(sharedLoadCall)
+	c.wg.Add(1)
+	sharedLoads.calls[key] = c
+	sharedLoads.Unlock()
+
+	func() {
+		// Waiting callers are released even if the load panics
+		defer func() {
+			sharedLoads.Lock()
+			delete(sharedLoads.calls, key)
+			sharedLoads.Unlock()
+			if c.app == nil && c.err == nil {
+				c.err = sdk.WithStack(fmt.Errorf("cannot load application %d", id))
+			}
+			c.wg.Done()
+		}()
+		c.app, c.err = LoadByID(db, id, opts...)
+	}()
+
+	return copySharedLoadResult(c)
+}
+
+func copySharedLoadResult(c *sharedLoadCall) (*sdk.Application, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	app := copyApplication(*c.app)
+	return &app, nil
+}
+
+// copyApplication returns a copy of given application that doesn't share any loaded slice or map with it.
+func copyApplication(app sdk.Application) sdk.Application {
+	if app.Variables != nil {
+		app.Variables = append([]sdk.ApplicationVariable{}, app.Variables...)
+	}
+	if app.Notifications != nil {
+		app.Notifications = append([]sdk.UserNotification{}, app.Notifications...)
+	}
+	if app.Keys != nil {
+		keys := make([]sdk.ApplicationKey, len(app.Keys))
+		for i, k := range app.Keys {
+			if k.ExpireAt != nil {
+				t := *k.ExpireAt
+				k.ExpireAt = &t
+			}
+			keys[i] = k
+		}
+		app.Keys = keys
+	}
+	if app.Vulnerabilities != nil {
+		app.Vulnerabilities = append([]sdk.Vulnerability{}, app.Vulnerabilities...)
+	}
+	if app.Metadata != nil {
+		metadata := make(sdk.Metadata, len(app.Metadata))
+		for k, v := range app.Metadata {
+			metadata[k] = v
+		}
+		app.Metadata = metadata
+	}
+	if app.DeploymentStrategies != nil {
+		strategies := make(map[string]sdk.IntegrationConfig, len(app.DeploymentStrategies))
+		for k, v := range app.DeploymentStrategies {
+			strategies[k] = v.Clone()
+		}
+		app.DeploymentStrategies = strategies
+	}
+	if app.Webhooks != nil {
+		webhooks := make([]sdk.ApplicationWebhook, len(app.Webhooks))
+		for i, wh := range app.Webhooks {
+			if wh.Events != nil {
+				wh.Events = append(sdk.StringSlice{}, wh.Events...)
+			}
+			webhooks[i] = wh
+		}
+		app.Webhooks = webhooks
+	}
+	if app.Pipelines != nil {
+		app.Pipelines = append([]sdk.Pipeline{}, app.Pipelines...)
+	}
+	if app.Usage != nil {
+		usage := *app.Usage
+		app.Usage = &usage
+	}
+	return app
+}
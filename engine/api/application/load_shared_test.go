@@ -0,0 +1,140 @@
+package application_test
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadByIDShared(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+	app := sdk.Application{
+		Name: "my-app",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "user",
+			Password:       "vcs_secret",
+		},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{Name: "var", Type: sdk.StringVariable, Value: "value"}, u))
+
+	const n = 10
+	apps := make([]*sdk.Application, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			apps[i], errs[i] = application.LoadByIDShared(db, app.ID, application.LoadOptions.WithVariables)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+		require.Equal(t, app.ID, apps[i].ID)
+		require.Equal(t, sdk.PasswordPlaceholder, apps[i].RepositoryStrategy.Password)
+		require.Len(t, apps[i].Variables, 1)
+	}
+
+	// Results are copies
+	apps[0].Variables[0].Value = "changed"
+	for i := 1; i < n; i++ {
+		require.NotSame(t, apps[0], apps[i])
+		require.Equal(t, "value", apps[i].Variables[0].Value)
+	}
+}
+
+// countingExecutor counts the queries sent to the database, each query is slowed down so concurrent calls overlap.
+type countingExecutor struct {
+	gorp.SqlExecutor
+	n *int64
+}
+
+func (c countingExecutor) count() {
+	atomic.AddInt64(c.n, 1)
+	time.Sleep(50 * time.Millisecond)
+}
+
+func (c countingExecutor) WithContext(ctx context.Context) gorp.SqlExecutor {
+	return countingExecutor{SqlExecutor: c.SqlExecutor.WithContext(ctx), n: c.n}
+}
+
+func (c countingExecutor) Select(i interface{}, query string, args ...interface{}) ([]interface{}, error) {
+	c.count()
+	return c.SqlExecutor.Select(i, query, args...)
+}
+
+func (c countingExecutor) SelectOne(holder interface{}, query string, args ...interface{}) error {
+	c.count()
+	return c.SqlExecutor.SelectOne(holder, query, args...)
+}
+
+func (c countingExecutor) SelectInt(query string, args ...interface{}) (int64, error) {
+	c.count()
+	return c.SqlExecutor.SelectInt(query, args...)
+}
+
+func (c countingExecutor) SelectNullStr(query string, args ...interface{}) (sql.NullString, error) {
+	c.count()
+	return c.SqlExecutor.SelectNullStr(query, args...)
+}
+
+func (c countingExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	c.count()
+	return c.SqlExecutor.Query(query, args...)
+}
+
+func (c countingExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	c.count()
+	return c.SqlExecutor.QueryRow(query, args...)
+}
+
+func TestLoadByIDSharedQueries(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	var single int64
+	_, err := application.LoadByIDShared(countingExecutor{SqlExecutor: db, n: &single}, app.ID, application.LoadOptions.WithVariables)
+	require.NoError(t, err)
+	require.NotZero(t, single)
+
+	// Concurrent callers share the queries of a single load
+	var shared int64
+	const n = 10
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = application.LoadByIDShared(countingExecutor{SqlExecutor: db, n: &shared}, app.ID, application.LoadOptions.WithVariables)
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+	}
+	require.Equal(t, single, shared)
+}
@@ -0,0 +1,52 @@
+package application
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+)
+
+// applicationLockClass is the first key of application advisory locks so they don't collide with other advisory locks.
+const applicationLockClass = 1001
+
+// Write functions of this package that read an application before writing it, such as Update, UpdateColumns,
+// ImportVariables or RenameVariable, lock it first. Concurrent writes on the same application are then serialized so
+// the stored signature always matches the stored row, and a write never uses flags or secrets read before a
+// concurrent write was committed. The last write wins, UpdateWithETag should be used to detect lost updates.
+// Writes on different applications are not serialized. Variables have their own signed rows, InsertVariable,
+// UpdateVariable and DeleteVariable don't lock the application.
+
+// LockApplication takes a transaction level advisory lock on an application, it waits until the lock is available
+// or given context is done. The lock is released when the transaction ends. It serializes complex operations such
+// as clone, merge or rename on an application without locking its rows.
+//
+// To avoid deadlocks, applications should be locked before any write in the transaction, and an operation that
+// needs several applications, such as merge or bulk updates, should lock all of them at once with LockApplications.
+func LockApplication(ctx context.Context, tx gorpmapper.SqlExecutorWithTx, appID int64) error {
+	if err := ctx.Err(); err != nil {
+		return sdk.WithStack(err)
+	}
+	// Second key is an int4, ids that only differ by 2^32 share a lock which is safe but serializes them
+	if _, err := tx.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock($1, $2::bigint::bit(32)::int4)", applicationLockClass, appID); err != nil {
+		return sdk.WrapError(err, "cannot lock application %d", appID)
+	}
+	return nil
+}
+
+// LockApplications locks given applications in ascending id order, see LockApplication.
+func LockApplications(ctx context.Context, tx gorpmapper.SqlExecutorWithTx, appIDs []int64) error {
+	ids := make([]int64, len(appIDs))
+	copy(ids, appIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for i, id := range ids {
+		if i > 0 && ids[i-1] == id {
+			continue
+		}
+		if err := LockApplication(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package application_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLockApplication(t *testing.T) {
+	db, _ := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback() // nolint
+
+	// Locks are reentrant in the same transaction
+	require.NoError(t, application.LockApplication(context.TODO(), tx, 1))
+	require.NoError(t, application.LockApplications(context.TODO(), tx, []int64{3, 1, 2, 3}))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	require.Error(t, application.LockApplication(ctx, tx, 1))
+}
+
+func TestLockApplicationsOverlappingBulk(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	var ids []int64
+	for i := 0; i < 4; i++ {
+		app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: fmt.Sprintf("my-app%d", i), FromRepository: "https://my-repo"})
+		ids = append(ids, app.ID)
+	}
+
+	// Two transactions detach overlapping sets given in opposite orders, both should complete
+	sets := [][]int64{{ids[0], ids[1], ids[2]}, {ids[3], ids[2], ids[1]}}
+	var wg sync.WaitGroup
+	errs := make([]error, len(sets))
+	for i := range sets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, err := db.Begin()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer tx.Rollback() // nolint
+			if _, err := application.DetachFromRepository(context.TODO(), tx, proj.ID, sets[i]); err != nil {
+				errs[i] = err
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+			errs[i] = tx.Commit()
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	for _, id := range ids {
+		app, err := application.LoadByID(db, id)
+		require.NoError(t, err)
+		require.Empty(t, app.FromRepository)
+	}
+}
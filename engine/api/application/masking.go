@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// MaskingPolicy defines the application fields masked for a caller, in addition to secrets that are always masked.
+// Masked fields are replaced by sdk.PasswordPlaceholder and keep their stored value on update.
+type MaskingPolicy struct {
+	// VCSUser masks the user of the vcs strategy.
+	VCSUser bool
+	// VCSServer masks the vcs server name.
+	VCSServer bool
+}
+
+// MaskingPolicies are the masking policies by caller role. Public masks every field that can be masked and should
+// be used for any caller which audience is not known.
+var MaskingPolicies = struct {
+	Admin  MaskingPolicy
+	User   MaskingPolicy
+	Public MaskingPolicy
+}{
+	Admin:  MaskingPolicy{},
+	User:   MaskingPolicy{VCSUser: true},
+	Public: MaskingPolicy{VCSUser: true, VCSServer: true},
+}
+
+// WithMaskingPolicy returns a load option that applies given masking policy, for loaders that don't receive
+// a context such as LoadAll. Without policy only secrets are masked.
+func WithMaskingPolicy(policy MaskingPolicy) LoadOptionFunc {
+	f := func(_ gorp.SqlExecutor, app *sdk.Application) error {
+		policy.apply(app)
+		return nil
+	}
+	return &f
+}
+
+// ContextWithMaskingPolicy returns a copy of the context that holds the masking policy of the caller.
+// Loaders that receive this context apply the policy, without policy they only mask secrets.
+func ContextWithMaskingPolicy(ctx context.Context, policy MaskingPolicy) context.Context {
+	return context.WithValue(ctx, contextMaskingPolicy, policy)
+}
+
+// ApplyMaskingPolicy applies the masking policy of given context to applications before they are returned to a
+// caller. MaskingPolicies.Public is applied if the context has no policy, callers opt in to less masking with
+// ContextWithMaskingPolicy.
+func ApplyMaskingPolicy(ctx context.Context, apps ...*sdk.Application) {
+	policy, has := ctx.Value(contextMaskingPolicy).(MaskingPolicy)
+	if !has {
+		policy = MaskingPolicies.Public
+	}
+	for _, app := range apps {
+		policy.apply(app)
+	}
+}
+
+// applyContextMaskingPolicy applies the masking policy of given context only if it has one, loaders are also used
+// by the engine that needs the unmasked vcs user and server.
+func applyContextMaskingPolicy(ctx context.Context, app *sdk.Application) {
+	if policy, has := ctx.Value(contextMaskingPolicy).(MaskingPolicy); has {
+		policy.apply(app)
+	}
+}
+
+func (p MaskingPolicy) apply(app *sdk.Application) {
+	if p.VCSUser && app.RepositoryStrategy.User != "" {
+		app.RepositoryStrategy.User = sdk.PasswordPlaceholder
+	}
+	if p.VCSServer && app.VCSServer != "" {
+		app.VCSServer = sdk.PasswordPlaceholder
+	}
+}
@@ -0,0 +1,101 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestMaskingPolicy(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "my-user",
+			Password:       "my-password",
+		},
+	})
+
+	adminCtx := application.ContextWithMaskingPolicy(context.TODO(), application.MaskingPolicies.Admin)
+	userCtx := application.ContextWithMaskingPolicy(context.TODO(), application.MaskingPolicies.User)
+
+	apps, err := application.LoadAllWithFilter(adminCtx, db, proj.ID, application.ApplicationFilter{})
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, "my-user", apps[0].RepositoryStrategy.User)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].RepositoryStrategy.Password)
+
+	apps, err = application.LoadAllWithFilter(userCtx, db, proj.ID, application.ApplicationFilter{})
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].RepositoryStrategy.User)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].RepositoryStrategy.Password)
+
+	// Without policy only secrets are masked
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-user", res.RepositoryStrategy.User)
+	application.ApplyMaskingPolicy(userCtx, res)
+	require.Equal(t, sdk.PasswordPlaceholder, res.RepositoryStrategy.User)
+
+	// Applied without policy, the public policy masks the vcs server too
+	res, err = application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	res.VCSServer = "github"
+	application.ApplyMaskingPolicy(context.TODO(), res)
+	require.Equal(t, sdk.PasswordPlaceholder, res.RepositoryStrategy.User)
+	require.Equal(t, sdk.PasswordPlaceholder, res.VCSServer)
+
+	// Masked values are kept on update
+	require.NoError(t, application.Update(userCtx, db, &apps[0]))
+	clearApp, err := application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-user", clearApp.RepositoryStrategy.User)
+	require.Equal(t, "my-password", clearApp.RepositoryStrategy.Password)
+}
+
+func TestWithMaskingPolicy(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name:      "my-app",
+		VCSServer: "my-vcs-server",
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "my-user",
+			Password:       "my-password",
+		},
+	})
+
+	apps, err := application.LoadAll(db, proj.Key, application.WithMaskingPolicy(application.MaskingPolicies.User))
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].RepositoryStrategy.User)
+	require.Equal(t, "my-vcs-server", apps[0].VCSServer)
+
+	apps, err = application.LoadAll(db, proj.Key, application.WithMaskingPolicy(application.MaskingPolicies.Public))
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].RepositoryStrategy.User)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].VCSServer)
+	require.Equal(t, sdk.PasswordPlaceholder, apps[0].RepositoryStrategy.Password)
+
+	// Masked values are kept on update
+	require.NoError(t, application.Update(context.TODO(), db, &apps[0]))
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-vcs-server", res.VCSServer)
+	require.Equal(t, "my-user", res.RepositoryStrategy.User)
+}
@@ -0,0 +1,91 @@
+package application
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// namePolicy is the compiled regexp that application names should match, nil means no policy.
+var namePolicy atomic.Value
+
+// SetNamePolicy sets the regexp that new application names should match in Insert and Update, an empty pattern
+// removes the policy. Existing applications that don't match it can still be updated if they are not renamed.
+func SetNamePolicy(pattern string) error {
+	if pattern == "" {
+		namePolicy.Store((*regexp.Regexp)(nil))
+		return nil
+	}
+	rx, err := compileNamePolicy(pattern)
+	if err != nil {
+		return err
+	}
+	namePolicy.Store(rx)
+	return nil
+}
+
+func compileNamePolicy(pattern string) (*regexp.Regexp, error) {
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid application name policy %q: %v", pattern, err)
+	}
+	return rx, nil
+}
+
+// checkNamePolicy returns sdk.ErrInvalidName if given name doesn't match the name policy.
+func checkNamePolicy(name string) error {
+	rx, _ := namePolicy.Load().(*regexp.Regexp)
+	if rx == nil || rx.MatchString(name) {
+		return nil
+	}
+	return sdk.NewErrorFrom(sdk.ErrInvalidName, "application name %q should match naming policy %s", name, rx.String())
+}
+
+// checkRenameNamePolicy checks the name policy only if given application is renamed.
+func checkRenameNamePolicy(db gorp.SqlExecutor, app sdk.Application) error {
+	if rx, _ := namePolicy.Load().(*regexp.Regexp); rx == nil {
+		return nil
+	}
+	name, err := db.SelectStr("SELECT name FROM application WHERE id = $1", app.ID)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load name of application %d", app.ID)
+	}
+	if name == app.Name {
+		return nil
+	}
+	return checkNamePolicy(app.Name)
+}
+
+// LoadAllViolatingNamePolicy returns the applications of a project ordered by name that don't match given name
+// policy regexp. It is used to clean existing data, nothing is written.
+func LoadAllViolatingNamePolicy(ctx context.Context, db gorp.SqlExecutor, projectID int64, policy string, opts ...LoadOptionFunc) ([]sdk.Application, error) {
+	rx, err := compileNamePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	names, err := LoadAllNames(ctx, db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, n := range names {
+		if !rx.MatchString(n.Name) {
+			ids = append(ids, n.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return []sdk.Application{}, nil
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT application.*
+	FROM application
+	WHERE application.id = ANY($1)
+	ORDER BY application.name ASC`).Args(pq.Int64Array(ids))
+	return getAll(ctx, db, opts, query)
+}
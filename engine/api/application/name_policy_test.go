@@ -0,0 +1,62 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestSetNamePolicyInvalid(t *testing.T) {
+	require.Error(t, application.SetNamePolicy("^[a-z"))
+	require.NoError(t, application.SetNamePolicy(""))
+}
+
+func TestNamePolicy(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	legacy := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "legacy"})
+
+	require.NoError(t, application.SetNamePolicy(`^[a-z]+-[a-z0-9-]+$`))
+	defer application.SetNamePolicy("") // nolint
+
+	// Matching and non matching names on insert
+	app := sdk.Application{Name: "team-service"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	err := application.Insert(context.TODO(), db, *proj, &sdk.Application{Name: "Service"})
+	require.True(t, sdk.ErrorIs(err, sdk.ErrInvalidName))
+
+	// Existing applications can be updated but not renamed to a non matching name
+	res, err := application.LoadByID(db, legacy.ID)
+	require.NoError(t, err)
+	res.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	res.Name = "other"
+	require.True(t, sdk.ErrorIs(application.Update(context.TODO(), db, res), sdk.ErrInvalidName))
+	res.Name = "team-legacy"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+
+	res.Name = "legacy"
+	require.NoError(t, application.SetNamePolicy(""))
+	require.NoError(t, application.Update(context.TODO(), db, res))
+
+	apps, err := application.LoadAllViolatingNamePolicy(context.TODO(), db, proj.ID, `^[a-z]+-[a-z0-9-]+$`)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, "legacy", apps[0].Name)
+
+	apps, err = application.LoadAllViolatingNamePolicy(context.TODO(), db, proj.ID, `^[a-z-]+$`)
+	require.NoError(t, err)
+	require.Empty(t, apps)
+
+	_, err = application.LoadAllViolatingNamePolicy(context.TODO(), db, proj.ID, "^[a-z")
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+}
@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// PollOptions configures PollModified.
+type PollOptions struct {
+	// MinInterval is the delay between two polls when applications are modified.
+	MinInterval time.Duration
+	// MaxInterval is the maximum delay between two polls when nothing is modified.
+	MaxInterval time.Duration
+	// Limit is the maximum number of changes read by poll.
+	Limit int
+}
+
+// IsValid returns an error if given options are not valid.
+func (o PollOptions) IsValid() error {
+	if o.MinInterval <= 0 || o.MaxInterval < o.MinInterval {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid poll intervals %v and %v", o.MinInterval, o.MaxInterval)
+	}
+	if o.Limit <= 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid limit %d", o.Limit)
+	}
+	return nil
+}
+
+// PollModified reads the applications changelog after given cursor and sends changes on returned channel.
+// Changes are read with ReadChanges so a change that commits after a later one is not skipped.
+// The delay between two polls starts at MinInterval and doubles each time nothing is read, up to MaxInterval.
+// It is reset to MinInterval when a change is read.
+// The consumer should store the cursor of the last received change to resume polling later.
+// Read errors are logged and handled as an empty poll. The channel is closed when the context is done.
+func PollModified(ctx context.Context, db gorp.SqlExecutor, after sdk.ApplicationChangeCursor, opts PollOptions) (<-chan sdk.ApplicationChange, error) {
+	if err := opts.IsValid(); err != nil {
+		return nil, err
+	}
+
+	changes := make(chan sdk.ApplicationChange)
+	go func() {
+		defer close(changes)
+
+		interval := opts.MinInterval
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			cs, err := ReadChanges(ctx, db, after, opts.Limit)
+			if err != nil && ctx.Err() == nil {
+				log.Error(ctx, "application.PollModified> unable to read changes after %+v: %v", after, err)
+			}
+			for _, c := range cs {
+				select {
+				case <-ctx.Done():
+					return
+				case changes <- c:
+				}
+				after = c.Cursor()
+			}
+
+			switch {
+			case len(cs) > 0:
+				interval = opts.MinInterval
+				timer.Reset(interval)
+			default:
+				timer.Reset(interval)
+				interval *= 2
+				if interval > opts.MaxInterval {
+					interval = opts.MaxInterval
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
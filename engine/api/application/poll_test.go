@@ -0,0 +1,54 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestPollModified(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	last, err := application.LastChangeCursor(context.TODO(), db)
+	require.NoError(t, err)
+
+	_, err = application.PollModified(context.TODO(), db, last, application.PollOptions{})
+	require.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	changes, err := application.PollModified(ctx, db, last, application.PollOptions{
+		MinInterval: 10 * time.Millisecond,
+		MaxInterval: 100 * time.Millisecond,
+		Limit:       1,
+	})
+	require.NoError(t, err)
+
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	app.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, &app))
+
+	var types []string
+	for c := range changes {
+		if c.ApplicationID != app.ID {
+			continue
+		}
+		types = append(types, c.Type)
+		if len(types) == 2 {
+			cancel()
+		}
+	}
+	require.Equal(t, []string{sdk.ApplicationChangeInsert, sdk.ApplicationChangeUpdate}, types)
+}
@@ -0,0 +1,61 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// Protected columns are not part of the signed data and can only be changed with their own setter, Update keeps
+// their stored value.
+const (
+	columnReadOnly          = "read_only"
+	columnFrozen            = "frozen"
+	columnRetentionDays     = "retention_days"
+	columnMaxConcurrentRuns = "max_concurrent_runs"
+	columnColor             = "color"
+)
+
+// setColumn sets the value of a protected column of an application, sdk.ErrNotFound is returned if the application
+// doesn't exist. Column should be one of the protected column constants. The change is recorded in the changelog.
+func setColumn(db gorp.SqlExecutor, appID int64, column string, value interface{}) error {
+	res, err := db.Exec("UPDATE application SET "+column+" = $2 WHERE id = $1", appID, value)
+	if err != nil {
+		return sdk.WrapError(err, "cannot set %s for application %d", column, appID)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	if n == 0 {
+		return sdk.WithStack(sdk.ErrNotFound)
+	}
+	return insertUpdateChanges(context.Background(), db, []int64{appID})
+}
+
+// loadColumn scans the value of a protected column of an application in dest, sdk.ErrNotFound is returned if the
+// application doesn't exist. Column should be one of the protected column constants.
+func loadColumn(db gorp.SqlExecutor, appID int64, column string, dest interface{}) error {
+	if err := db.QueryRow("SELECT "+column+" FROM application WHERE id = $1", appID).Scan(dest); err != nil {
+		if err == sql.ErrNoRows {
+			return sdk.WithStack(sdk.ErrNotFound)
+		}
+		return sdk.WrapError(err, "cannot load %s for application %d", column, appID)
+	}
+	return nil
+}
+
+// loadProtectedColumns sets the stored value of all protected columns on given application with a single query.
+func loadProtectedColumns(db gorp.SqlExecutor, app *sdk.Application) error {
+	if err := db.QueryRow("SELECT "+columnReadOnly+", "+columnFrozen+", "+columnRetentionDays+", "+columnMaxConcurrentRuns+", "+columnColor+" FROM application WHERE id = $1", app.ID).
+		Scan(&app.ReadOnly, &app.Frozen, &app.RetentionDays, &app.MaxConcurrentRuns, &app.Color); err != nil {
+		if err == sql.ErrNoRows {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "application %d not found", app.ID)
+		}
+		return sdk.WrapError(err, "cannot load protected columns for application %d", app.ID)
+	}
+	return nil
+}
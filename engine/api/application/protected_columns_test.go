@@ -0,0 +1,87 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestProtectedColumns(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	tests := []struct {
+		name    string
+		set     func(db gorp.SqlExecutor, appID int64) error
+		invalid func(db gorp.SqlExecutor, appID int64) error
+		// change sets another value on a loaded application, check returns true if it has the value of set
+		change func(app *sdk.Application)
+		check  func(app sdk.Application) bool
+	}{
+		{
+			name:   "read only",
+			set:    func(db gorp.SqlExecutor, appID int64) error { return application.SetReadOnly(db, appID, true) },
+			change: func(app *sdk.Application) { app.ReadOnly = false },
+			check:  func(app sdk.Application) bool { return app.ReadOnly },
+		},
+		{
+			name:   "frozen",
+			set:    application.Freeze,
+			change: func(app *sdk.Application) { app.Frozen = false },
+			check:  func(app sdk.Application) bool { return app.Frozen },
+		},
+		{
+			name:    "retention days",
+			set:     func(db gorp.SqlExecutor, appID int64) error { return application.SetRetentionDays(db, appID, 90) },
+			invalid: func(db gorp.SqlExecutor, appID int64) error { return application.SetRetentionDays(db, appID, -1) },
+			change:  func(app *sdk.Application) { app.RetentionDays = 1 },
+			check:   func(app sdk.Application) bool { return app.RetentionDays == 90 },
+		},
+		{
+			name:    "max concurrent runs",
+			set:     func(db gorp.SqlExecutor, appID int64) error { return application.SetMaxConcurrentRuns(db, appID, 3) },
+			invalid: func(db gorp.SqlExecutor, appID int64) error { return application.SetMaxConcurrentRuns(db, appID, -1) },
+			change:  func(app *sdk.Application) { app.MaxConcurrentRuns = 10 },
+			check:   func(app sdk.Application) bool { return app.MaxConcurrentRuns == 3 },
+		},
+		{
+			name:    "color",
+			set:     func(db gorp.SqlExecutor, appID int64) error { return application.SetColor(db, appID, "teal") },
+			invalid: func(db gorp.SqlExecutor, appID int64) error { return application.SetColor(db, appID, "not-a-color") },
+			change:  func(app *sdk.Application) { app.Color = "red" },
+			check:   func(app sdk.Application) bool { return app.Color == "teal" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: sdk.RandomString(10)})
+
+			if tt.invalid != nil {
+				require.True(t, sdk.ErrorIs(tt.invalid(db, app.ID), sdk.ErrWrongRequest))
+			}
+			require.NoError(t, tt.set(db, app.ID))
+			require.True(t, sdk.ErrorIs(tt.set(db, 0), sdk.ErrNotFound))
+
+			// The value is surfaced by loaders and can't be changed with an update
+			res, err := application.LoadByID(db, app.ID)
+			require.NoError(t, err)
+			require.True(t, tt.check(*res))
+			tt.change(res)
+			require.NoError(t, application.Update(application.ContextWithReadOnlyOverride(context.TODO()), db, res))
+			res, err = application.LoadByID(db, app.ID)
+			require.NoError(t, err)
+			require.True(t, tt.check(*res))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// ContextWithReadOnlyOverride returns a copy of the context that allows to update read only applications.
+// It should only be used by the as code reconciler.
+func ContextWithReadOnlyOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextReadOnlyOverride, true)
+}
+
+func hasReadOnlyOverride(ctx context.Context) bool {
+	override, _ := ctx.Value(contextReadOnlyOverride).(bool)
+	return override
+}
+
+// SetReadOnly sets if an application can be updated. The flag is not part of the signed data.
+func SetReadOnly(db gorp.SqlExecutor, appID int64, ro bool) error {
+	return setColumn(db, appID, columnReadOnly, ro)
+}
+
+func isReadOnly(db gorp.SqlExecutor, appID int64) (bool, error) {
+	var ro bool
+	err := loadColumn(db, appID, columnReadOnly, &ro)
+	return ro, err
+}
+
+// CheckWritable returns sdk.ErrForbidden if the application is read only and given context has no override,
+// or if given context is marked with ContextWithReadOnlyLoad.
+func CheckWritable(ctx context.Context, db gorp.SqlExecutor, appID int64) error {
+	if err := checkNotReadOnlyLoad(ctx); err != nil {
+		return err
+	}
+	if hasReadOnlyOverride(ctx) {
+		return nil
+	}
+	ro, err := isReadOnly(db, appID)
+	if err != nil {
+		return err
+	}
+	return checkWritable(ctx, appID, ro)
+}
+
+// checkWritable is CheckWritable for an application which read only flag is already loaded.
+func checkWritable(ctx context.Context, appID int64, ro bool) error {
+	if err := checkNotReadOnlyLoad(ctx); err != nil {
+		return err
+	}
+	if ro && !hasReadOnlyOverride(ctx) {
+		return sdk.NewErrorFrom(sdk.ErrForbidden, "application %d is read only", appID)
+	}
+	return nil
+}
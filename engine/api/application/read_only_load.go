@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// ContextWithReadOnlyLoad returns a copy of the context that marks loads as read only, any write attempted by a
+// load option returns an error instead of reaching the database. Insert and CheckWritable also fail with this context.
+// It should be used when loading from a read replica.
+func ContextWithReadOnlyLoad(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextReadOnlyLoad, true)
+}
+
+func isReadOnlyLoad(ctx context.Context) bool {
+	ro, _ := ctx.Value(contextReadOnlyLoad).(bool)
+	return ro
+}
+
+func checkNotReadOnlyLoad(ctx context.Context) error {
+	if isReadOnlyLoad(ctx) {
+		return errReadOnlyLoad()
+	}
+	return nil
+}
+
+func errReadOnlyLoad() error {
+	return sdk.NewErrorFrom(sdk.ErrForbidden, "write attempted in a read only load")
+}
+
+// readOnlyLoadExecutor rejects all writes except the ones made with raw queries through Select or Query.
+type readOnlyLoadExecutor struct {
+	gorp.SqlExecutor
+}
+
+// readOnlyLoadDB returns given db wrapped to reject writes if given context is marked as read only.
+func readOnlyLoadDB(ctx context.Context, db gorp.SqlExecutor) gorp.SqlExecutor {
+	if !isReadOnlyLoad(ctx) {
+		return db
+	}
+	if _, ok := db.(readOnlyLoadExecutor); ok {
+		return db
+	}
+	return readOnlyLoadExecutor{db}
+}
+
+func (e readOnlyLoadExecutor) WithContext(ctx context.Context) gorp.SqlExecutor {
+	return readOnlyLoadExecutor{e.SqlExecutor.WithContext(ctx)}
+}
+
+func (e readOnlyLoadExecutor) Insert(list ...interface{}) error {
+	return errReadOnlyLoad()
+}
+
+func (e readOnlyLoadExecutor) Update(list ...interface{}) (int64, error) {
+	return 0, errReadOnlyLoad()
+}
+
+func (e readOnlyLoadExecutor) UpdateColumns(filter gorp.ColumnFilter, list ...interface{}) (int64, error) {
+	return 0, errReadOnlyLoad()
+}
+
+func (e readOnlyLoadExecutor) Delete(list ...interface{}) (int64, error) {
+	return 0, errReadOnlyLoad()
+}
+
+func (e readOnlyLoadExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, errReadOnlyLoad()
+}
@@ -0,0 +1,41 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestReadOnlyLoad(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	backfill := func(db gorp.SqlExecutor, app *sdk.Application) error {
+		_, err := db.Exec("UPDATE application SET description = 'backfilled' WHERE id = $1", app.ID)
+		return err
+	}
+	ro := application.ContextWithReadOnlyLoad(context.TODO())
+
+	_, _, err := application.LoadByIDWithETag(ro, db, app.ID, &backfill)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrForbidden))
+	res, _, err := application.LoadByIDWithETag(ro, db, app.ID, application.LoadOptions.WithVariables)
+	require.NoError(t, err)
+	require.Empty(t, res.Description)
+
+	require.True(t, sdk.ErrorIs(application.CheckWritable(ro, db, app.ID), sdk.ErrForbidden))
+	require.True(t, sdk.ErrorIs(application.Insert(ro, db, *proj, &sdk.Application{Name: "my-other-app"}), sdk.ErrForbidden))
+
+	_, _, err = application.LoadByIDWithETag(context.TODO(), db, app.ID, &backfill)
+	require.NoError(t, err)
+}
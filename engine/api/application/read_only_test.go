@@ -0,0 +1,80 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestReadOnly(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	u, _ := assets.InsertLambdaUser(t, db)
+
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	require.NoError(t, application.SetReadOnly(db, app.ID, true))
+
+	app.Description = "my description"
+	err := application.Update(context.TODO(), db, &app)
+	require.Error(t, err)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrForbidden))
+	require.Error(t, application.CheckWritable(context.TODO(), db, app.ID))
+
+	// The as code reconciler can update a read only application
+	require.NoError(t, application.Update(application.ContextWithReadOnlyOverride(context.TODO()), db, &app))
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my description", res.Description)
+	require.True(t, res.ReadOnly)
+
+	// Variables of a read only application can only be changed by the as code reconciler
+	v := sdk.ApplicationVariable{Name: "my-var", Type: sdk.StringVariable, Value: "value"}
+	require.True(t, sdk.ErrorIs(application.InsertVariable(context.TODO(), db, app.ID, &v, u), sdk.ErrForbidden))
+	require.NoError(t, application.InsertVariable(application.ContextWithReadOnlyOverride(context.TODO()), db, app.ID, &v, u))
+	v.Value = "new value"
+	require.True(t, sdk.ErrorIs(application.UpdateVariable(context.TODO(), db, app.ID, &v, nil, u), sdk.ErrForbidden))
+	require.True(t, sdk.ErrorIs(application.DeleteVariable(context.TODO(), db, app.ID, &v, u), sdk.ErrForbidden))
+
+	// Child rows and the application itself can't be changed either
+	require.True(t, sdk.ErrorIs(application.AddRepository(context.TODO(), db, app.ID, "my/repo"), sdk.ErrForbidden))
+	require.True(t, sdk.ErrorIs(application.RemoveRepository(context.TODO(), db, app.ID, "my/repo"), sdk.ErrForbidden))
+	wh := sdk.ApplicationWebhook{ApplicationID: app.ID, URL: "https://my-hook", Events: []string{sdk.ApplicationChangeUpdate}}
+	require.True(t, sdk.ErrorIs(application.InsertWebhook(context.TODO(), db, &wh), sdk.ErrForbidden))
+	require.True(t, sdk.ErrorIs(application.DeleteWebhook(context.TODO(), db, app.ID, 1), sdk.ErrForbidden))
+	k := sdk.ApplicationKey{ApplicationID: app.ID, Type: sdk.KeyTypeSSH, Name: "app-my-key"}
+	require.True(t, sdk.ErrorIs(application.InsertKey(context.TODO(), db, &k), sdk.ErrForbidden))
+	require.True(t, sdk.ErrorIs(application.DeleteApplication(context.TODO(), db, app.ID), sdk.ErrForbidden))
+
+	require.NoError(t, application.SetReadOnly(db, app.ID, false))
+	require.NoError(t, application.CheckWritable(context.TODO(), db, app.ID))
+	require.NoError(t, application.DeleteVariable(context.TODO(), db, app.ID, &v, u))
+}
+
+func TestInsertResetsProtectedColumns(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	app := sdk.Application{Name: "my-app", ReadOnly: true, Frozen: true, RetentionDays: 7, MaxConcurrentRuns: 2, Color: "blue"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.False(t, res.ReadOnly)
+	require.False(t, res.Frozen)
+	require.Equal(t, int64(0), res.RetentionDays)
+	require.Equal(t, int64(0), res.MaxConcurrentRuns)
+	require.Equal(t, "", res.Color)
+}
@@ -0,0 +1,23 @@
+package application
+
+import (
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// SetRetentionDays sets the number of days the workflow runs of an application are kept by the purge,
+// zero means that only the retention policy of the workflow applies. Retention is not part of the signed data.
+func SetRetentionDays(db gorp.SqlExecutor, appID, days int64) error {
+	if days < 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid retention days %d", days)
+	}
+	return setColumn(db, appID, columnRetentionDays, days)
+}
+
+// LoadRetentionDays returns the number of days the workflow runs of an application are kept by the purge.
+func LoadRetentionDays(db gorp.SqlExecutor, appID int64) (int64, error) {
+	var days int64
+	err := loadColumn(db, appID, columnRetentionDays, &days)
+	return days, err
+}
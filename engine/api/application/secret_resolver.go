@@ -0,0 +1,59 @@
+package application
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// SecretResolver returns the value of an external secret given its reference.
+type SecretResolver func(ctx context.Context, ref string) (string, error)
+
+var (
+	secretResolverMutex sync.RWMutex
+	secretResolver      SecretResolver
+)
+
+// SetSecretResolver sets the resolver of vcs strategy password references. Without resolver Insert and Update
+// reject password references, and loading an application stored with one and its clear password fails.
+func SetSecretResolver(resolver SecretResolver) {
+	secretResolverMutex.Lock()
+	defer secretResolverMutex.Unlock()
+	secretResolver = resolver
+}
+
+// checkSecretResolver returns sdk.ErrWrongRequest if given application has a password reference and no resolver is
+// set, it could not be loaded with its clear password.
+func checkSecretResolver(app sdk.Application) error {
+	if app.RepositoryStrategy.PasswordRef == "" {
+		return nil
+	}
+	secretResolverMutex.RLock()
+	defer secretResolverMutex.RUnlock()
+	if secretResolver == nil {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "application %s: password references are not supported, no secret resolver is configured", app.Name)
+	}
+	return nil
+}
+
+// resolveVCSStrategyPassword sets the vcs strategy password of given application from its password reference.
+// The resolved password is never stored, Insert and Update clear the password of an application with a reference.
+func resolveVCSStrategyPassword(ctx context.Context, app *sdk.Application) error {
+	ref := app.RepositoryStrategy.PasswordRef
+	if ref == "" {
+		return nil
+	}
+	secretResolverMutex.RLock()
+	resolver := secretResolver
+	secretResolverMutex.RUnlock()
+	if resolver == nil {
+		return sdk.NewErrorFrom(sdk.ErrNotImplemented, "application %s: no resolver for password reference %s", app.Name, ref)
+	}
+	password, err := resolver(ctx, ref)
+	if err != nil {
+		return sdk.WrapError(err, "cannot resolve password reference %s of application %s", ref, app.Name)
+	}
+	app.RepositoryStrategy.Password = password
+	return nil
+}
@@ -0,0 +1,72 @@
+package application_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestVCSStrategyPasswordRef(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+	t.Cleanup(func() { application.SetSecretResolver(nil) })
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	strategy := sdk.RepositoryStrategy{
+		ConnectionType: "https",
+		User:           "my-user",
+		Password:       "my-inline-password",
+		PasswordRef:    "vault://secret/my-repo",
+	}
+
+	// Password references are rejected without resolver
+	err := application.Insert(context.TODO(), db, *proj, &sdk.Application{Name: "my-app", RepositoryStrategy: strategy})
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	application.SetSecretResolver(func(ctx context.Context, ref string) (string, error) {
+		if ref != "vault://secret/my-repo" {
+			return "", fmt.Errorf("unknown secret %s", ref)
+		}
+		return "my-resolved-password", nil
+	})
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app", RepositoryStrategy: strategy})
+
+	has, err := application.HasVCSPassword(db, app.ID)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	res, err := application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-resolved-password", res.RepositoryStrategy.Password)
+	require.Equal(t, "vault://secret/my-repo", res.RepositoryStrategy.PasswordRef)
+	res, err = application.LoadByNameWithClearVCSStrategyPassword(context.TODO(), db, proj.Key, app.Name)
+	require.NoError(t, err)
+	require.Equal(t, "my-resolved-password", res.RepositoryStrategy.Password)
+	apps, err := application.LoadAllByIDsWithDecryption(db, []int64{app.ID})
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, "my-resolved-password", apps[0].RepositoryStrategy.Password)
+
+	// Updating the loaded application doesn't store the resolved password
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	res.RepositoryStrategy.PasswordRef = ""
+	res.RepositoryStrategy.Password = sdk.PasswordPlaceholder
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	has, err = application.HasVCSPassword(db, app.ID)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// An application stored with a reference can't be loaded with its clear password without resolver
+	other := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-other-app", RepositoryStrategy: strategy})
+	application.SetSecretResolver(nil)
+	_, err = application.LoadByIDWithClearVCSStrategyPassword(context.TODO(), db, other.ID)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotImplemented))
+}
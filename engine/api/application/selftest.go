@@ -0,0 +1,180 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/engine/gorpmapper"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// selfTestMaxFailureRatio is the part of invalid signatures in the sample above which the self test fails.
+// A few corrupted applications can exist, but most invalid signatures means a wrong signature key.
+const selfTestMaxFailureRatio = 0.5
+
+// SelfTest checks the signature of a random sample of applications and returns an error if too many
+// of them are invalid, which means the signature key is probably misconfigured.
+func SelfTest(ctx context.Context, db gorp.SqlExecutor, sampleSize int) error {
+	if sampleSize <= 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid sample size %d", sampleSize)
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT id, project_id, name, sig
+	FROM application
+	ORDER BY random()
+	LIMIT $1`).Args(sampleSize)
+	var res []dbApplication
+	if err := gorpmapping.GetAll(ctx, db, query, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		return nil
+	}
+
+	var invalids []int64
+	for i := range res {
+		isValid, err := gorpmapping.CheckSignature(res[i], res[i].Signature)
+		if err != nil {
+			return err
+		}
+		if !isValid {
+			invalids = append(invalids, res[i].ID)
+		}
+	}
+	if len(invalids) > 0 {
+		log.Warning(ctx, "application.SelfTest> %d/%d applications with invalid signature: %v", len(invalids), len(res), invalids)
+	}
+	if float64(len(invalids)) > selfTestMaxFailureRatio*float64(len(res)) {
+		return sdk.WithStack(fmt.Errorf("%d/%d checked applications have an invalid signature, check the database signature keys", len(invalids), len(res)))
+	}
+	return nil
+}
+
+// anyCorruptedBatchSize is the number of signatures read at once by AnyCorrupted and RepairSignatures.
+const anyCorruptedBatchSize = 100
+
+// AnyCorrupted returns true as soon as an application of given project with an invalid signature is found.
+// Applications are read by batches so the scan stops early, it returns an error if given context is done.
+func AnyCorrupted(ctx context.Context, db gorp.SqlExecutor, projectID int64) (bool, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return false, err
+	}
+	var lastID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, sdk.WithStack(err)
+		}
+		query := gorpmapping.NewQuery(`
+		SELECT id, project_id, name, sig
+		FROM application
+		WHERE project_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`).Args(projectID, lastID, anyCorruptedBatchSize)
+		var res []dbApplication
+		if err := gorpmapping.GetAll(ctx, db, query, &res); err != nil {
+			return false, err
+		}
+		for i := range res {
+			isValid, err := gorpmapping.CheckSignature(res[i], res[i].Signature)
+			if err != nil {
+				return false, err
+			}
+			if !isValid {
+				return true, nil
+			}
+		}
+		if len(res) < anyCorruptedBatchSize {
+			return false, nil
+		}
+		lastID = res[len(res)-1].ID
+	}
+}
+
+// RepairSignatures re-signs the applications of given project which signature is invalid or missing, valid ones
+// are not written. The stored row is trusted, so it should only be used once the cause of the corruption is known.
+// Secrets encrypted with another project or name than the stored ones stay undecryptable. Applications are read by
+// batches and locked before being re-signed, running it again repairs nothing.
+func RepairSignatures(ctx context.Context, db gorpmapper.SqlExecutorWithTx, projectID int64) (int, error) {
+	if err := checkProjectID(projectID); err != nil {
+		return 0, err
+	}
+	var lastID int64
+	var checked, repaired int
+	for {
+		if err := ctx.Err(); err != nil {
+			return repaired, sdk.WithStack(err)
+		}
+		query := gorpmapping.NewQuery(`
+		SELECT id, project_id, name, sig
+		FROM application
+		WHERE project_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`).Args(projectID, lastID, anyCorruptedBatchSize)
+		var res []dbApplication
+		if err := gorpmapping.GetAll(ctx, db, query, &res); err != nil {
+			return repaired, err
+		}
+		var invalids []int64
+		for i := range res {
+			isValid, err := isSignatureValid(res[i])
+			if err != nil {
+				return repaired, err
+			}
+			if !isValid {
+				invalids = append(invalids, res[i].ID)
+			}
+		}
+		for _, id := range invalids {
+			ok, err := repairSignature(ctx, db, id)
+			if err != nil {
+				return repaired, err
+			}
+			if ok {
+				repaired++
+			}
+		}
+		checked += len(res)
+		if len(res) < anyCorruptedBatchSize {
+			break
+		}
+		lastID = res[len(res)-1].ID
+	}
+	log.Info(ctx, "application.RepairSignatures> %d/%d applications of project %d repaired", repaired, checked, projectID)
+	return repaired, nil
+}
+
+func isSignatureValid(app dbApplication) (bool, error) {
+	if len(app.Signature) == 0 {
+		return false, nil
+	}
+	return gorpmapping.CheckSignature(app, app.Signature)
+}
+
+// repairSignature locks an application and re-signs it if its signature is still invalid.
+func repairSignature(ctx context.Context, db gorpmapper.SqlExecutorWithTx, appID int64) (bool, error) {
+	if err := LockApplication(ctx, db, appID); err != nil {
+		return false, err
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT id, project_id, name, sig
+	FROM application
+	WHERE id = $1`).Args(appID)
+	var app dbApplication
+	found, err := gorpmapping.Get(ctx, db, query, &app)
+	if err != nil || !found {
+		return false, err
+	}
+	isValid, err := isSignatureValid(app)
+	if err != nil || isValid {
+		return false, err
+	}
+	// Only the signature is written, it is computed from the loaded signed fields
+	if err := gorpmapping.Sign(ctx, db, &app); err != nil {
+		return false, sdk.WrapError(err, "cannot re-sign application %d", appID)
+	}
+	return true, nil
+}
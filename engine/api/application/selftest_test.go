@@ -0,0 +1,88 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestSelfTest(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := sdk.Application{Name: "my-app"}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	require.NoError(t, application.SelfTest(context.TODO(), db, 10))
+	require.Error(t, application.SelfTest(context.TODO(), db, 0))
+}
+
+func TestAnyCorrupted(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+	assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-other-app"})
+
+	corrupted, err := application.AnyCorrupted(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.False(t, corrupted)
+
+	_, err = db.Exec("UPDATE application SET name = 'my-corrupted-app' WHERE id = $1", app.ID)
+	require.NoError(t, err)
+	corrupted, err = application.AnyCorrupted(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.True(t, corrupted)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err = application.AnyCorrupted(ctx, db, proj.ID)
+	require.Error(t, err)
+}
+
+func TestRepairSignatures(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app1 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app1"})
+	app2 := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app2"})
+	healthy := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app3"})
+
+	var sig []byte
+	require.NoError(t, db.SelectOne(&sig, "SELECT sig FROM application WHERE id = $1", healthy.ID))
+
+	_, err := db.Exec("UPDATE application SET name = 'my-corrupted-app' WHERE id = $1", app1.ID)
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE application SET sig = NULL WHERE id = $1", app2.ID)
+	require.NoError(t, err)
+
+	repaired, err := application.RepairSignatures(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, repaired)
+
+	corrupted, err := application.AnyCorrupted(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.False(t, corrupted)
+	res, err := application.LoadByID(db, app1.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my-corrupted-app", res.Name)
+
+	// Healthy applications are not written
+	var newSig []byte
+	require.NoError(t, db.SelectOne(&newSig, "SELECT sig FROM application WHERE id = $1", healthy.ID))
+	require.Equal(t, sig, newSig)
+
+	repaired, err = application.RepairSignatures(context.TODO(), db, proj.ID)
+	require.NoError(t, err)
+	require.Zero(t, repaired)
+}
@@ -0,0 +1,47 @@
+package application
+
+import (
+	"github.com/ovh/cds/sdk"
+)
+
+// BatchValidationError is the error of the application at given index of a validated batch.
+type BatchValidationError struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	Err   error  `json:"-"`
+}
+
+// ValidateBatch checks given applications as Insert would without touching the database, and reports all the
+// invalid ones. Names should be unique in the batch and must not be in existingNames, that can be built from
+// LoadAllNames. Given applications are not modified. An error is returned only if the batch contains a nil
+// application.
+func ValidateBatch(apps []*sdk.Application, existingNames map[string]bool) ([]BatchValidationError, error) {
+	var res []BatchValidationError
+	seen := make(map[string]int, len(apps))
+	for i, app := range apps {
+		if app == nil {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid nil application at index %d", i)
+		}
+		a := *app
+		a.Description = sdk.RemoveControlCharacters(a.Description)
+		err := a.IsValid()
+		if err == nil {
+			err = checkNamePolicy(a.Name)
+		}
+		if err == nil {
+			err = checkSecretResolver(a)
+		}
+		if first, has := seen[a.Name]; err == nil && has {
+			err = sdk.NewErrorFrom(sdk.ErrApplicationExist, "application %s is duplicated at index %d", a.Name, first)
+		} else if err == nil && existingNames[a.Name] {
+			err = sdk.NewErrorFrom(sdk.ErrApplicationExist, "application %s already exists", a.Name)
+		}
+		if _, has := seen[a.Name]; !has {
+			seen[a.Name] = i
+		}
+		if err != nil {
+			res = append(res, BatchValidationError{Index: i, Name: a.Name, Err: err})
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package application_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestValidateBatch(t *testing.T) {
+	apps := []*sdk.Application{
+		{Name: "my-app1"},
+		{Name: "my app"},
+		{Name: "my-app1"},
+		{Name: "my-app2"},
+		{Name: "my-app3"},
+	}
+
+	res, err := application.ValidateBatch(apps, map[string]bool{"my-app2": true})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	require.Equal(t, 1, res[0].Index)
+	require.True(t, sdk.ErrorIs(res[0].Err, sdk.ErrInvalidName))
+	require.Equal(t, 2, res[1].Index)
+	require.Equal(t, "my-app1", res[1].Name)
+	require.True(t, sdk.ErrorIs(res[1].Err, sdk.ErrApplicationExist))
+	require.Equal(t, 3, res[2].Index)
+	require.True(t, sdk.ErrorIs(res[2].Err, sdk.ErrApplicationExist))
+
+	res, err = application.ValidateBatch(apps[3:], nil)
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	_, err = application.ValidateBatch([]*sdk.Application{nil}, nil)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	// Names are checked against the name policy like in Insert
+	require.NoError(t, application.SetNamePolicy("^team-"))
+	t.Cleanup(func() { _ = application.SetNamePolicy("") })
+	res, err = application.ValidateBatch([]*sdk.Application{{Name: "team-app"}, {Name: "my-app"}}, nil)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, 1, res[0].Index)
+	require.True(t, sdk.ErrorIs(res[0].Err, sdk.ErrInvalidName))
+}
@@ -0,0 +1,69 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type contextKey int
+
+const (
+	contextAccessor contextKey = iota
+	contextReadOnlyOverride
+	contextIdempotencyKey
+	contextMaskingPolicy
+	contextReadOnlyLoad
+	contextKeepEmptyVCSPassword
+	contextMigration
+)
+
+// ContextWithAccessor returns a copy of the context that holds the identity of the caller
+// that will be recorded when clear secrets are loaded.
+func ContextWithAccessor(ctx context.Context, accessor string) context.Context {
+	return context.WithValue(ctx, contextAccessor, accessor)
+}
+
+// AccessorFromContext returns the identity of the caller set with ContextWithAccessor.
+func AccessorFromContext(ctx context.Context) string {
+	accessor, _ := ctx.Value(contextAccessor).(string)
+	return accessor
+}
+
+// VCSPasswordAccess is an audit record of a clear vcs strategy password access.
+// It never contains the password itself.
+type VCSPasswordAccess struct {
+	ApplicationID int64     `json:"application_id"`
+	Accessor      string    `json:"accessor"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// VCSPasswordAccessSink receives an audit record each time a clear vcs strategy password is loaded.
+type VCSPasswordAccessSink func(ctx context.Context, access VCSPasswordAccess)
+
+var (
+	vcsPasswordAccessSinkMutex sync.RWMutex
+	vcsPasswordAccessSink      VCSPasswordAccessSink
+)
+
+// SetVCSPasswordAccessSink sets the sink that receives vcs strategy password access records.
+// Given nil sink disables the audit.
+func SetVCSPasswordAccessSink(sink VCSPasswordAccessSink) {
+	vcsPasswordAccessSinkMutex.Lock()
+	defer vcsPasswordAccessSinkMutex.Unlock()
+	vcsPasswordAccessSink = sink
+}
+
+func auditVCSPasswordAccess(ctx context.Context, appID int64) {
+	vcsPasswordAccessSinkMutex.RLock()
+	sink := vcsPasswordAccessSink
+	vcsPasswordAccessSinkMutex.RUnlock()
+	if sink == nil {
+		return
+	}
+	sink(ctx, VCSPasswordAccess{
+		ApplicationID: appID,
+		Accessor:      AccessorFromContext(ctx),
+		Timestamp:     time.Now(),
+	})
+}
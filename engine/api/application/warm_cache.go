@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// WarmCache preloads the applications of given projects in the id cache, masked as LoadByID results, and in the
+// exists cache. It does nothing for a disabled cache and stops between projects when given context is done.
+func WarmCache(ctx context.Context, db gorp.SqlExecutor, projectIDs []int64) error {
+	if !isIDCacheEnabled() && !isExistsCacheEnabled() {
+		return nil
+	}
+	for _, id := range projectIDs {
+		if err := ctx.Err(); err != nil {
+			return sdk.WithStack(err)
+		}
+		var rows []struct {
+			ID         int64  `db:"id"`
+			Version    string `db:"version"`
+			ProjectKey string `db:"projectkey"`
+			Name       string `db:"name"`
+		}
+		if _, err := db.WithContext(ctx).Select(&rows, `
+		SELECT application.id, application.xmin::text AS version, project.projectkey, application.name
+		FROM application
+		JOIN project ON project.id = application.project_id
+		WHERE application.project_id = $1`, id); err != nil {
+			return sdk.WrapError(err, "cannot load applications of project %d", id)
+		}
+		for _, r := range rows {
+			setExistsCache(r.ProjectKey, r.Name, true)
+		}
+		if !isIDCacheEnabled() {
+			continue
+		}
+		// Applications written after their version was loaded are cached with an outdated version and ignored by LoadByID
+		query := gorpmapping.NewQuery(`
+		SELECT application.*
+		FROM application
+		WHERE application.project_id = $1`).Args(id)
+		apps, err := getAll(context.Background(), db.WithContext(ctx), nil, query)
+		if err != nil {
+			return err
+		}
+		versions := make(map[int64]string, len(rows))
+		for _, r := range rows {
+			versions[r.ID] = r.Version
+		}
+		for _, app := range apps {
+			version, ok := versions[app.ID]
+			if !ok {
+				continue
+			}
+			app.RepositoryStrategy.Password = sdk.PasswordPlaceholder
+			app.RepositoryStrategy.SSHKeyContent = ""
+			setIDCache(version, app)
+		}
+	}
+	return nil
+}
+
+// LoadTopProjectIDsByUsage returns the ids of at most limit projects which applications were the most recently used
+// by workflow runs, the most recent first.
+func LoadTopProjectIDsByUsage(db gorp.SqlExecutor, limit int) ([]int64, error) {
+	if limit <= 0 {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid limit %d", limit)
+	}
+	var res []int64
+	if _, err := db.Select(&res, `
+	SELECT project_id
+	FROM application
+	WHERE last_used_at IS NOT NULL
+	GROUP BY project_id
+	ORDER BY MAX(last_used_at) DESC
+	LIMIT $1`, limit); err != nil {
+		return nil, sdk.WrapError(err, "cannot load top projects by usage")
+	}
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestWarmCache(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	application.EnableExistsCache(time.Minute, 10)
+	t.Cleanup(application.DisableExistsCache)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: "my-app"})
+
+	require.NoError(t, application.WarmCache(context.TODO(), db, []int64{proj.ID}))
+
+	// Remove the application without invalidating the cache, Exists should be answered by the cache
+	_, err := db.Exec("DELETE FROM application WHERE id = $1", app.ID)
+	require.NoError(t, err)
+
+	exists, err := application.Exists(db, proj.Key, "my-app")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	require.Error(t, application.WarmCache(ctx, db, []int64{proj.ID}))
+}
+
+func TestWarmCacheLoadByID(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	application.EnableIDCache(time.Minute, 10)
+	t.Cleanup(application.DisableIDCache)
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{
+		Name:     "my-app",
+		Metadata: sdk.Metadata{"team": "a"},
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "user",
+			Password:       "vcs_secret",
+		},
+	})
+
+	require.NoError(t, application.WarmCache(context.TODO(), db, []int64{proj.ID}))
+
+	res, err := application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, sdk.PasswordPlaceholder, res.RepositoryStrategy.Password)
+	require.Equal(t, "a", res.Metadata["team"])
+
+	// A cached application is a copy and is reloaded once its row is written
+	res.Metadata["team"] = "b"
+	res, err = application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "a", res.Metadata["team"])
+
+	res.Description = "my description"
+	require.NoError(t, application.Update(context.TODO(), db, res))
+	res, err = application.LoadByID(db, app.ID)
+	require.NoError(t, err)
+	require.Equal(t, "my description", res.Description)
+
+	require.NoError(t, application.DeleteApplication(context.TODO(), db, app.ID))
+	_, err = application.LoadByID(db, app.ID)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
+
+func TestLoadTopProjectIDsByUsage(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	_, err := application.LoadTopProjectIDsByUsage(db, 0)
+	require.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	key1, key2 := sdk.RandomString(10), sdk.RandomString(10)
+	proj1 := assets.InsertTestProject(t, db, cache, key1, key1)
+	proj2 := assets.InsertTestProject(t, db, cache, key2, key2)
+	app1 := assets.InsertTestApplication(t, db, proj1, sdk.Application{Name: "my-app1"})
+	app2 := assets.InsertTestApplication(t, db, proj2, sdk.Application{Name: "my-app2"})
+
+	now := time.Now()
+	require.NoError(t, application.UpdateLastUsed(db, app1.ID, now.Add(24*time.Hour)))
+	require.NoError(t, application.UpdateLastUsed(db, app2.ID, now.Add(48*time.Hour)))
+
+	ids, err := application.LoadTopProjectIDsByUsage(db, 2)
+	require.NoError(t, err)
+	require.Equal(t, []int64{proj2.ID, proj1.ID}, ids)
+}
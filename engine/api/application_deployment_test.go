@@ -30,7 +30,7 @@ func Test_getApplicationDeploymentStrategiesConfigHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	vars := map[string]string{
 		"permProjectKey":  proj.Key,
@@ -57,7 +57,7 @@ func Test_postApplicationDeploymentStrategyConfigHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	pf := sdk.IntegrationModel{
 		Name:       "test-deploy-post-2" + pkey,
@@ -180,7 +180,7 @@ func Test_postApplicationDeploymentStrategyConfigHandler_InsertTwoDifferentInteg
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	pf := sdk.IntegrationModel{
 		Name:       "test-deploy-TwoDifferentIntegrations-2" + pkey,
@@ -305,7 +305,7 @@ func Test_postApplicationDeploymentStrategyConfigHandlerAsProvider(t *testing.T)
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	pf := sdk.IntegrationModel{
 		Name:       "test-deploy-3" + pkey,
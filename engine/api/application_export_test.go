@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http/httptest"
 	"testing"
 
@@ -22,7 +23,7 @@ func Test_getApplicationExportHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: appName,
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -32,7 +33,7 @@ func Test_getApplicationExportHandler(t *testing.T) {
 		Type:  sdk.StringVariable,
 	}
 
-	test.NoError(t, application.InsertVariable(db, app.ID, &v1, u))
+	test.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &v1, u))
 
 	v2 := sdk.ApplicationVariable{
 		Name:  "var2",
@@ -40,7 +41,7 @@ func Test_getApplicationExportHandler(t *testing.T) {
 		Type:  sdk.SecretVariable,
 	}
 
-	test.NoError(t, application.InsertVariable(db, app.ID, &v2, u))
+	test.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &v2, u))
 
 	//Insert ssh and gpg keys
 	k := &sdk.ApplicationKey{
@@ -54,7 +55,7 @@ func Test_getApplicationExportHandler(t *testing.T) {
 	k.Public = kk.Public
 	k.Private = kk.Private
 	k.KeyID = kk.KeyID
-	test.NoError(t, application.InsertKey(db, k))
+	test.NoError(t, application.InsertKey(context.TODO(), db, k))
 
 	k2 := &sdk.ApplicationKey{
 		Name:          "mykey-ssh",
@@ -67,7 +68,7 @@ func Test_getApplicationExportHandler(t *testing.T) {
 	k2.Public = kssh.Public
 	k2.Private = kssh.Private
 	k2.KeyID = kssh.KeyID
-	test.NoError(t, application.InsertKey(db, k2))
+	test.NoError(t, application.InsertKey(context.TODO(), db, k2))
 
 	//Prepare request
 	vars := map[string]string{
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http/httptest"
 	"strings"
@@ -100,7 +101,7 @@ func Test_postApplicationImportHandler_NewAppFromYAMLWithKeysAndSecrets(t *testi
 	app := &sdk.Application{
 		Name: "myNewApp",
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	k := &sdk.ApplicationKey{
 		Name:          "app-mykey",
@@ -113,11 +114,11 @@ func Test_postApplicationImportHandler_NewAppFromYAMLWithKeysAndSecrets(t *testi
 	k.Public = kpgp.Public
 	k.Private = kpgp.Private
 	k.KeyID = kpgp.KeyID
-	if err := application.InsertKey(db, k); err != nil {
+	if err := application.InsertKey(context.TODO(), db, k); err != nil {
 		t.Fatal(err)
 	}
 
-	test.NoError(t, application.InsertVariable(db, app.ID, &sdk.ApplicationVariable{
+	test.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{
 		Name:  "myPassword",
 		Type:  sdk.SecretVariable,
 		Value: "MySecretValue",
@@ -208,7 +209,7 @@ func Test_postApplicationImportHandler_NewAppFromYAMLWithKeysAndSecretsAndReImpo
 	app := &sdk.Application{
 		Name: "myNewApp",
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	k := &sdk.ApplicationKey{
 		Name:          "app-mykey",
@@ -221,11 +222,11 @@ func Test_postApplicationImportHandler_NewAppFromYAMLWithKeysAndSecretsAndReImpo
 	k.Public = kpgp.Public
 	k.Private = kpgp.Private
 	k.KeyID = kpgp.KeyID
-	if err := application.InsertKey(db, k); err != nil {
+	if err := application.InsertKey(context.TODO(), db, k); err != nil {
 		t.Fatal(err)
 	}
 
-	test.NoError(t, application.InsertVariable(db, app.ID, &sdk.ApplicationVariable{
+	test.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{
 		Name:  "myPassword",
 		Type:  sdk.SecretVariable,
 		Value: "MySecretValue",
@@ -371,7 +372,7 @@ func Test_postApplicationImportHandler_NewAppFromYAMLWithKeysAndSecretsAndReImpo
 	app := &sdk.Application{
 		Name: "myNewApp",
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	// create password, pgp and ssh keys
 	k1 := &sdk.ApplicationKey{
@@ -385,7 +386,7 @@ func Test_postApplicationImportHandler_NewAppFromYAMLWithKeysAndSecretsAndReImpo
 	k1.Public = kpgp.Public
 	k1.Private = kpgp.Private
 	k1.KeyID = kpgp.KeyID
-	test.NoError(t, application.InsertKey(db, k1))
+	test.NoError(t, application.InsertKey(context.TODO(), db, k1))
 
 	// create password, pgp and ssh keys
 	k2 := &sdk.ApplicationKey{
@@ -399,9 +400,9 @@ func Test_postApplicationImportHandler_NewAppFromYAMLWithKeysAndSecretsAndReImpo
 	k2.Public = kssh.Public
 	k2.Private = kssh.Private
 	k2.KeyID = kssh.KeyID
-	test.NoError(t, application.InsertKey(db, k2))
+	test.NoError(t, application.InsertKey(context.TODO(), db, k2))
 
-	test.NoError(t, application.InsertVariable(db, app.ID, &sdk.ApplicationVariable{
+	test.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &sdk.ApplicationVariable{
 		Name:  "myPassword",
 		Type:  sdk.SecretVariable,
 		Value: "MySecretValue",
@@ -573,7 +574,7 @@ func Test_postApplicationImportHandler_ExistingAppFromYAMLWithoutForce(t *testin
 	app := sdk.Application{
 		Name: "myNewApp",
 	}
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	//Prepare request
 	vars := map[string]string{
@@ -607,7 +608,7 @@ func Test_postApplicationImportHandler_ExistingAppFromYAMLInheritPermissions(t *
 	app := sdk.Application{
 		Name: "myNewApp",
 	}
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	//Prepare request
 	vars := map[string]string{
@@ -667,7 +668,7 @@ func Test_postApplicationImportHandler_ExistingAppWithDeploymentStrategy(t *test
 	app := sdk.Application{
 		Name: "myNewApp",
 	}
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	test.NoError(t, application.SetDeploymentStrategy(db, proj.ID, app.ID, pf.ID, pp.Name, sdk.IntegrationConfig{
 		"token": sdk.IntegrationConfigValue{
@@ -778,7 +779,7 @@ func Test_postApplicationImportHandler_DontOverrideDeploymentPasswordIfNotGiven(
 	app := sdk.Application{
 		Name: "myNewApp",
 	}
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	test.NoError(t, application.SetDeploymentStrategy(db, proj.ID, app.ID, pf.ID, pp.Name, sdk.IntegrationConfig{
 		"token": sdk.IntegrationConfigValue{
@@ -118,7 +118,7 @@ func (api *API) addKeyInApplicationHandler() service.Handler {
 		}
 		defer tx.Rollback() // nolint
 
-		if err := application.InsertKey(tx, &newKey); err != nil {
+		if err := application.InsertKey(ctx, tx, &newKey); err != nil {
 			return sdk.WrapError(err, "Cannot insert application key")
 		}
 
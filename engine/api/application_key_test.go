@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -30,7 +31,7 @@ func Test_getKeysInApplicationHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -49,7 +50,7 @@ func Test_getKeysInApplicationHandler(t *testing.T) {
 	k.Private = pgpK.Private
 	k.KeyID = pgpK.KeyID
 
-	if err := application.InsertKey(db, k); err != nil {
+	if err := application.InsertKey(context.TODO(), db, k); err != nil {
 		t.Fatal(err)
 	}
 
@@ -88,7 +89,7 @@ func Test_deleteKeyInApplicationHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -100,7 +101,7 @@ func Test_deleteKeyInApplicationHandler(t *testing.T) {
 		ApplicationID: app.ID,
 	}
 
-	if err := application.InsertKey(db, k); err != nil {
+	if err := application.InsertKey(context.TODO(), db, k); err != nil {
 		t.Fatal(err)
 	}
 
@@ -140,7 +141,7 @@ func Test_addKeyInApplicationHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -54,7 +54,7 @@ func Test_postApplicationMetadataHandler_AsProvider(t *testing.T) {
 			"a1": "a1",
 		},
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -246,7 +246,7 @@ func TestUpdateAsCodeApplicationHandler(t *testing.T) {
 		VCSServer:          "github",
 		FromRepository:     "myrepofrom",
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	repoModel, err := workflow.LoadHookModelByName(db, sdk.RepositoryWebHookModelName)
@@ -297,3 +297,43 @@ func TestUpdateAsCodeApplicationHandler(t *testing.T) {
 		require.Equal(t, "myURL", ae.Event.PullRequestURL)
 	}
 }
+
+func Test_updateApplicationHandlerMasksVCSUser(t *testing.T) {
+	api, db, router := newTestAPI(t)
+
+	pkey := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, pkey, pkey)
+	u, pass := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+
+	app := &sdk.Application{
+		Name: sdk.RandomString(10),
+		RepositoryStrategy: sdk.RepositoryStrategy{
+			ConnectionType: "https",
+			User:           "vcs-user",
+			Password:       "vcs-password",
+		},
+	}
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, app))
+
+	vars := map[string]string{
+		"permProjectKey":  pkey,
+		"applicationName": app.Name,
+	}
+	uri := router.GetRoute("PUT", api.updateApplicationHandler, vars)
+	app.Description = "my description"
+	app.RepositoryStrategy.User = sdk.PasswordPlaceholder
+	req := assets.NewAuthentifiedRequest(t, u, pass, "PUT", uri, app)
+	w := httptest.NewRecorder()
+	router.Mux.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var res sdk.Application
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, "my description", res.Description)
+	require.Equal(t, sdk.PasswordPlaceholder, res.RepositoryStrategy.User)
+	require.Equal(t, sdk.PasswordPlaceholder, res.RepositoryStrategy.Password)
+
+	stored, err := application.LoadByNameWithClearVCSStrategyPassword(context.TODO(), db, pkey, app.Name)
+	require.NoError(t, err)
+	require.Equal(t, "vcs-user", stored.RepositoryStrategy.User)
+}
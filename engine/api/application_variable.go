@@ -80,10 +80,16 @@ func (api *API) getVariablesInApplicationHandler() service.Handler {
 		key := vars[permProjectKey]
 		appName := vars["applicationName"]
 
-		app, err := application.LoadByName(api.mustDB(), key, appName, application.LoadOptions.WithVariables)
+		// Secrets are decrypted to report the ones that can't be, so one unrecoverable secret doesn't fail the list
+		app, err := application.LoadByName(api.mustDB(), key, appName, application.LoadOptions.WithVariablesWithClearPasswordSkipUndecryptable)
 		if err != nil {
 			return sdk.WrapError(err, "Cannot load application %s", appName)
 		}
+		for i := range app.Variables {
+			if sdk.NeedPlaceholder(app.Variables[i].Type) && !app.Variables[i].Undecryptable {
+				app.Variables[i].Value = sdk.PasswordPlaceholder
+			}
+		}
 
 		return service.WriteJSON(w, app.Variables, http.StatusOK)
 	}
@@ -115,7 +121,7 @@ func (api *API) deleteVariableFromApplicationHandler() service.Handler {
 			return sdk.WrapError(errV, "deleteVariableFromApplicationHandler> Cannot load variable %s", varName)
 		}
 
-		if err := application.DeleteVariable(tx, app.ID, varToDelete, getAPIConsumer(ctx)); err != nil {
+		if err := application.DeleteVariable(ctx, tx, app.ID, varToDelete, getAPIConsumer(ctx)); err != nil {
 			return sdk.WrapError(err, "Cannot delete %s", varName)
 		}
 
@@ -163,7 +169,7 @@ func (api *API) updateVariableInApplicationHandler() service.Handler {
 		}
 		defer tx.Rollback() // nolint
 
-		if err := application.UpdateVariable(tx, app.ID, &newVar, variableBefore, getAPIConsumer(ctx)); err != nil {
+		if err := application.UpdateVariable(ctx, tx, app.ID, &newVar, variableBefore, getAPIConsumer(ctx)); err != nil {
 			return sdk.WrapError(err, "Cannot update variable %s for application %s", varName, appName)
 		}
 
@@ -211,7 +217,7 @@ func (api *API) addVariableInApplicationHandler() service.Handler {
 		}
 		defer tx.Rollback() // nolint
 
-		if err = application.InsertVariable(tx, app.ID, &newVar, getAPIConsumer(ctx)); err != nil {
+		if err = application.InsertVariable(ctx, tx, app.ID, &newVar, getAPIConsumer(ctx)); err != nil {
 			return sdk.WrapError(err, "Cannot add variable %s in application %s", varName, appName)
 		}
 
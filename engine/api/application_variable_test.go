@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -27,7 +28,7 @@ func Test_getVariableAuditInApplicationHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -37,7 +38,7 @@ func Test_getVariableAuditInApplicationHandler(t *testing.T) {
 		Type:  "string",
 		Value: "bar",
 	}
-	if err := application.InsertVariable(db, app.ID, &v, u); err != nil {
+	if err := application.InsertVariable(context.TODO(), db, app.ID, &v, u); err != nil {
 		t.Fatal(err)
 	}
 
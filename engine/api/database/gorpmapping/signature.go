@@ -23,6 +23,11 @@ func UpdateColumnsAndSign(ctx context.Context, db gorpmapper.SqlExecutorWithTx,
 	return Mapper.UpdateColumnsAndSign(ctx, db, i, colFilter)
 }
 
+// Sign only updates the signature of a data in database, given data should implement canonicaller interface.
+func Sign(ctx context.Context, db gorpmapper.SqlExecutorWithTx, i gorpmapper.Canonicaller) error {
+	return Mapper.Sign(ctx, db, i)
+}
+
 func CheckSignature(i gorpmapper.Canonicaller, sig []byte) (bool, error) {
 	return Mapper.CheckSignature(i, sig)
 }
@@ -0,0 +1,50 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// ApplicationVariableNameIndex creates the unique index on application variable names ignoring case without locking
+// the table. It fails if variables that only differ by case exist, they can be listed by project with
+// application.FindDuplicateVariables and the migration reset once they are renamed or removed.
+func ApplicationVariableNameIndex(ctx context.Context, db *gorp.DbMap) error {
+	var ids []int64
+	if _, err := db.WithContext(ctx).Select(&ids, `
+	SELECT DISTINCT application_id
+	FROM application_variable
+	GROUP BY application_id, lower(var_name)
+	HAVING COUNT(1) > 1
+	ORDER BY application_id`); err != nil {
+		return sdk.WrapError(err, "cannot find duplicate application variables")
+	}
+	if len(ids) > 0 {
+		return sdk.WithStack(fmt.Errorf("application variables that only differ by case must be renamed or removed before creating idx_application_variable_lower_name, applications: %v", ids))
+	}
+
+	// A failed concurrent build leaves an invalid index that must be dropped before building it again
+	invalid, err := db.WithContext(ctx).SelectInt(`
+	SELECT COUNT(1)
+	FROM pg_index
+	JOIN pg_class ON pg_class.oid = pg_index.indexrelid
+	WHERE pg_class.relname = 'idx_application_variable_lower_name' AND NOT pg_index.indisvalid`)
+	if err != nil {
+		return sdk.WrapError(err, "cannot check idx_application_variable_lower_name")
+	}
+	if invalid > 0 {
+		log.Warning(ctx, "migrate.ApplicationVariableNameIndex> dropping invalid index idx_application_variable_lower_name")
+		if _, err := db.WithContext(ctx).Exec("DROP INDEX CONCURRENTLY IF EXISTS idx_application_variable_lower_name"); err != nil {
+			return sdk.WrapError(err, "cannot drop invalid index idx_application_variable_lower_name")
+		}
+	}
+
+	if _, err := db.WithContext(ctx).Exec(`CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_application_variable_lower_name ON "application_variable" (application_id, lower(var_name))`); err != nil {
+		return sdk.WrapError(err, "cannot create index idx_application_variable_lower_name")
+	}
+	return nil
+}
@@ -153,7 +153,7 @@ func TestLoadByWorkflowID(t *testing.T) {
 		ProjectKey: proj.Key,
 		ProjectID:  proj.ID,
 	}
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	pip := sdk.Pipeline{
 		ProjectID:  proj.ID,
@@ -30,7 +30,7 @@ func deleteAll(t *testing.T, api *API, key string) error {
 	}
 	for _, app := range apps {
 		tx, _ := api.mustDB().Begin()
-		if err := application.DeleteApplication(tx, app.ID); err != nil {
+		if err := application.DeleteApplication(context.TODO(), tx, app.ID); err != nil {
 			t.Logf("DeleteApplication: %s", err)
 			return err
 		}
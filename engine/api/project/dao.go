@@ -34,7 +34,10 @@ func LoadAllByRepoAndGroupIDs(ctx context.Context, db gorp.SqlExecutor, groupIDs
 	query := `SELECT DISTINCT project.*
 		FROM  project
 		JOIN  application on project.id = application.project_id
-		WHERE application.repo_fullname = $3
+		WHERE (
+			application.repo_fullname = $3
+			OR application.id IN (SELECT application_id FROM application_repository WHERE repo_fullname = $3)
+		)
 		AND   project.id IN (
 			SELECT project_group.project_id
 			FROM project_group
@@ -56,6 +59,7 @@ func LoadAllByRepo(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 	FROM  project
 	JOIN  application on project.id = application.project_id
 	WHERE application.repo_fullname = $1
+	OR application.id IN (SELECT application_id FROM application_repository WHERE repo_fullname = $1)
 	ORDER by project.name, project.projectkey ASC`
 	args := []interface{}{repo}
 	return loadAllByRepo(ctx, db, query, args, opts...)
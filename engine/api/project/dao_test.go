@@ -59,7 +59,7 @@ func TestLoadAllByRepo(t *testing.T) {
 
 	app, _ := application.LoadByName(db, "TestLoadAllByRepo", "TestLoadAllByRepo")
 	if app != nil {
-		application.DeleteApplication(db, app.ID)
+		application.DeleteApplication(context.TODO(), db, app.ID)
 	}
 	project.Delete(db, "TestLoadAllByRepo")
 	defer project.Delete(db, "TestLoadAllByRepo")
@@ -94,7 +94,7 @@ func TestLoadAllByRepo(t *testing.T) {
 
 	u, _ := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
 
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	projs, err := project.LoadAllByRepoAndGroupIDs(context.TODO(), db, u.GetGroupIDs(), "ovh/cds")
 	assert.NoError(t, err)
@@ -1,6 +1,7 @@
 package project
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/go-gorp/gorp"
@@ -84,7 +85,7 @@ func loadApplications(db gorp.SqlExecutor, proj *sdk.Project) error {
 }
 
 func loadApplicationNames(db gorp.SqlExecutor, proj *sdk.Project) error {
-	apps, err := application.LoadAllNames(db, proj.ID)
+	apps, err := application.LoadAllNames(context.Background(), db, proj.ID)
 	if err != nil {
 		return sdk.WithStack(err)
 	}
@@ -140,6 +140,11 @@ func applyRetentionPolicyOnRun(ctx context.Context, db *gorp.DbMap, wf sdk.Workf
 		}
 		return false, nil
 	}
+	// Runs younger than the retention of the application are always kept
+	if app.RetentionDays > 0 && time.Since(run.LastModified) < time.Duration(app.RetentionDays)*24*time.Hour {
+		return true, nil
+	}
+
 	luacheck, err := luascript.NewCheck()
 	if err != nil {
 		return true, sdk.WithStack(err)
@@ -49,7 +49,7 @@ func InsertTestProject(t *testing.T, db gorpmapper.SqlExecutorWithTx, store cach
 			require.NoError(t, workflow.Delete(context.TODO(), db, store, *oldProj, &w))
 		}
 		for _, app := range oldProj.Applications {
-			require.NoError(t, application.DeleteApplication(db, app.ID))
+			require.NoError(t, application.DeleteApplication(context.TODO(), db, app.ID))
 		}
 		for _, pip := range oldProj.Pipelines {
 			require.NoError(t, pipeline.DeletePipeline(context.TODO(), db, pip.ID))
@@ -586,3 +586,30 @@ func InsertTestWorkflow(t *testing.T, db gorpmapper.SqlExecutorWithTx, store cac
 
 	return &w
 }
+
+// InsertTestApplication creates a signed test application in given project with given fields.
+// Variables and keys of the application are inserted too. A random name is used if the name is empty.
+func InsertTestApplication(t *testing.T, db gorpmapper.SqlExecutorWithTx, proj *sdk.Project, app sdk.Application) *sdk.Application {
+	if app.Name == "" {
+		app.Name = sdk.RandomString(10)
+	}
+	vars, keys := app.Variables, app.Keys
+	app.Variables, app.Keys = nil, nil
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+
+	u := &sdk.AuthentifiedUser{Username: "test"}
+	for i := range vars {
+		require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &vars[i], u))
+	}
+	for i := range keys {
+		keys[i].ApplicationID = app.ID
+		require.NoError(t, application.InsertKey(context.TODO(), db, &keys[i]))
+	}
+
+	// Loading the application checks the signature of all inserted rows
+	res, err := application.LoadByID(db, app.ID, application.LoadOptions.WithVariables, application.LoadOptions.WithKeys)
+	require.NoError(t, err)
+	require.Len(t, res.Variables, len(vars))
+	require.Len(t, res.Keys, len(keys))
+	return res
+}
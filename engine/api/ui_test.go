@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
@@ -25,8 +26,8 @@ func Test_getNavbarHandler(t *testing.T) {
 	app2 := sdk.Application{
 		Name: "my-app-2",
 	}
-	test.NoError(t, application.Insert(db, *proj, &app1))
-	test.NoError(t, application.Insert(db, *proj, &app2))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app1))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
 
 	//Prepare request
 	uri := api.Router.GetRoute("GET", api.getNavbarHandler, nil)
@@ -1121,7 +1121,7 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 		if opts != nil {
 			fromRepo = opts.FromRepository
 		}
-		appDB, appSecrets, msgList, err := application.ParseAndImport(ctx, tx, store, *proj, &app, application.ImportOptions{Force: true, FromRepository: fromRepo}, decryptFunc, u)
+		appDB, appSecrets, msgList, err := application.ParseAndImport(application.ContextWithReadOnlyOverride(ctx), tx, store, *proj, &app, application.ImportOptions{Force: true, FromRepository: fromRepo}, decryptFunc, u)
 		allMsg = append(allMsg, msgList...)
 		if err != nil {
 			return allMsg, nil, nil, nil, sdk.ErrorWithFallback(err, sdk.ErrWrongRequest, "unable to import application %s/%s", proj.Key, app.Name)
@@ -1200,7 +1200,7 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 			if app.FromRepository != "" {
 				continue
 			}
-			appSecrets, err := LoadApplicationSecrets(db, id)
+			appSecrets, err := LoadApplicationSecrets(ctx, db, id)
 			if err != nil {
 				return nil, nil, nil, nil, err
 			}
@@ -1220,7 +1220,7 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 
 	if wf.WorkflowData.Node.Context.ApplicationID != 0 {
 		app := wf.Applications[wf.WorkflowData.Node.Context.ApplicationID]
-		if err := application.Update(tx, &app); err != nil {
+		if err := application.Update(application.ContextWithReadOnlyOverride(ctx), tx, &app); err != nil {
 			return nil, nil, nil, nil, sdk.WrapError(err, "Unable to update application vcs datas")
 		}
 		wf.Applications[wf.WorkflowData.Node.Context.ApplicationID] = app
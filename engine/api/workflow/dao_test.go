@@ -162,7 +162,7 @@ func TestInsertSimpleWorkflowWithApplicationAndEnv(t *testing.T) {
 		Name:       "app1",
 	}
 
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	env := sdk.Environment{
 		ProjectID:  proj.ID,
@@ -529,7 +529,7 @@ func TestUpdateSimpleWorkflowWithApplicationEnvPipelineParametersAndPayload(t *t
 		Name:       "app1",
 	}
 
-	test.NoError(t, application.Insert(db, *proj, &app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	app2 := sdk.Application{
 		ProjectID:  proj.ID,
@@ -537,7 +537,7 @@ func TestUpdateSimpleWorkflowWithApplicationEnvPipelineParametersAndPayload(t *t
 		Name:       "app2",
 	}
 
-	test.NoError(t, application.Insert(db, *proj, &app2))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, &app2))
 
 	env := sdk.Environment{
 		ProjectID:  proj.ID,
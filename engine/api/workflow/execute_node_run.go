@@ -12,6 +12,7 @@ import (
 	"github.com/go-gorp/gorp"
 
 	"github.com/ovh/cds/engine/api/action"
+	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/group"
 	"github.com/ovh/cds/engine/api/plugin"
 	"github.com/ovh/cds/engine/api/repositoriesmanager"
@@ -312,6 +313,15 @@ func executeNodeRun(ctx context.Context, db gorpmapper.SqlExecutorWithTx, store
 				return report, err
 			}
 		}
+
+		// If current node has an application, we want to trigger another node run that can be waiting for its concurrent runs limit
+		if workflowNodeRun.ApplicationID != 0 {
+			r, err := releaseConcurrentRuns(ctx, db, store, proj, workflowNodeRun.ApplicationID)
+			report.Merge(ctx, r)
+			if err != nil {
+				return report, err
+			}
+		}
 	}
 	return report, nil
 }
@@ -381,6 +391,63 @@ func releaseMutex(ctx context.Context, db gorpmapper.SqlExecutorWithTx, store ca
 	return r, nil
 }
 
+// releaseConcurrentRuns executes the oldest node run of given application that is waiting for its concurrent runs
+// limit, if the limit is not reached anymore.
+func releaseConcurrentRuns(ctx context.Context, db gorpmapper.SqlExecutorWithTx, store cache.Store, proj sdk.Project, appID int64) (*ProcessorReport, error) {
+	_, next := telemetry.Span(ctx, "workflow.releaseConcurrentRuns")
+	defer next()
+
+	if err := application.LockConcurrentRuns(ctx, db, []int64{appID}); err != nil {
+		return nil, err
+	}
+
+	// A node run waiting for the limit has no job run yet
+	query := `
+    SELECT workflow_node_run.id
+    FROM workflow_node_run
+    WHERE workflow_node_run.application_id = $1
+      AND workflow_node_run.status = $2
+      AND NOT EXISTS (SELECT 1 FROM workflow_node_run_job WHERE workflow_node_run_job.workflow_node_run_id = workflow_node_run.id)
+    ORDER BY workflow_node_run.id ASC
+    LIMIT 1
+  `
+	waitingRunID, err := db.SelectInt(query, appID, string(sdk.StatusWaiting))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, sdk.WrapError(err, "unable to load workflow node run waiting for application %d", appID)
+	}
+	if waitingRunID == 0 {
+		return nil, nil
+	}
+	reached, err := application.ConcurrentRunsReached(db, appID, waitingRunID)
+	if err != nil {
+		return nil, err
+	}
+	if reached {
+		return nil, nil
+	}
+
+	waitingRun, err := LoadNodeRunByID(db, waitingRunID, LoadRunOptions{})
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load workflow node run %d", waitingRunID)
+	}
+	workflowRun, err := LoadRunByID(db, waitingRun.WorkflowRunID, LoadRunOptions{})
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load workflow run %d", waitingRun.WorkflowRunID)
+	}
+
+	AddWorkflowRunInfo(workflowRun, sdk.SpawnMsgNew(*sdk.MsgWorkflowNodeMutexRelease, waitingRun.WorkflowNodeName))
+	if err := UpdateWorkflowRun(ctx, db, workflowRun); err != nil {
+		return nil, sdk.WrapError(err, "unable to update workflow run %d after concurrent runs release", workflowRun.ID)
+	}
+
+	log.Debug("workflow.execute> process the node run %d because application %d concurrent runs limit has been released", waitingRun.ID, appID)
+	r, err := executeNodeRun(ctx, db, store, proj, waitingRun)
+	if err != nil {
+		return r, sdk.WrapError(err, "unable to reprocess workflow")
+	}
+	return r, nil
+}
+
 func checkRunOnlyFailedJobs(wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun) (*sdk.WorkflowNodeRun, error) {
 	var previousNR *sdk.WorkflowNodeRun
 	nrs, ok := wr.WorkflowNodeRuns[nr.WorkflowNodeID]
@@ -914,6 +981,25 @@ func StopWorkflowNodeRun(ctx context.Context, dbFunc func() *gorp.DbMap, store c
 		}
 	}
 
+	// If current node has an application, we want to trigger another node run that can be waiting for its concurrent runs limit
+	if workflowNodeRun.ApplicationID != 0 {
+		tx, err := dbFunc().Begin()
+		if err != nil {
+			return report, sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		r, err := releaseConcurrentRuns(ctx, tx, store, proj, workflowNodeRun.ApplicationID)
+		report.Merge(ctx, r)
+		if err != nil {
+			return report, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return report, err
+		}
+	}
+
 	return report, nil
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-gorp/gorp"
 
+	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/cache"
 	"github.com/ovh/cds/engine/gorpmapper"
 	"github.com/ovh/cds/sdk"
@@ -44,6 +45,10 @@ func processWorkflowDataRun(ctx context.Context, db gorpmapper.SqlExecutorWithTx
 	}
 	//////
 
+	if err := lockConcurrentRuns(ctx, db, wr); err != nil {
+		return nil, false, err
+	}
+
 	//// Process Report
 	oldStatus := wr.Status
 	report := new(ProcessorReport)
@@ -115,6 +120,15 @@ func processWorkflowDataRun(ctx context.Context, db gorpmapper.SqlExecutorWithTx
 	return report, true, nil
 }
 
+// lockConcurrentRuns locks at once the applications of the workflow run that have a concurrent runs limit.
+func lockConcurrentRuns(ctx context.Context, db gorpmapper.SqlExecutorWithTx, wr *sdk.WorkflowRun) error {
+	appIDs := make([]int64, 0, len(wr.Workflow.Applications))
+	for id := range wr.Workflow.Applications {
+		appIDs = append(appIDs, id)
+	}
+	return application.LockConcurrentRuns(ctx, db, appIDs)
+}
+
 func computeAndUpdateWorkflowRunStatus(ctx context.Context, db gorp.SqlExecutor, wr *sdk.WorkflowRun) (*ProcessorReport, error) {
 	report := new(ProcessorReport)
 	// Recompute status counter, it's mandatory to resync
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/repositoriesmanager"
 	"github.com/ovh/cds/engine/cache"
 	"github.com/ovh/cds/engine/gorpmapper"
@@ -109,6 +110,20 @@ func processNode(ctx context.Context, db gorpmapper.SqlExecutorWithTx, store cac
 	if n.Context.PipelineID == 0 && n.Type == sdk.NodeTypePipeline {
 		return nil, false, sdk.ErrPipelineNotFound
 	}
+	if n.Context.ApplicationID != 0 {
+		frozen, err := application.LoadFrozen(db, n.Context.ApplicationID)
+		if err != nil {
+			return nil, false, err
+		}
+		if frozen {
+			return nil, false, sdk.NewErrorFrom(sdk.ErrForbidden, "application %d is frozen", n.Context.ApplicationID)
+		}
+		if err := application.CheckDeployTarget(db, n.Context.ApplicationID,
+			wr.Workflow.Environments[n.Context.EnvironmentID].Name,
+			wr.Workflow.ProjectIntegrations[n.Context.ProjectIntegrationID].Name); err != nil {
+			return nil, false, err
+		}
+	}
 
 	nr := createWorkflowNodeRun(wr, n, parents, subNumber, hookEvent, manual)
 
@@ -380,6 +395,22 @@ func processNode(ctx context.Context, db gorpmapper.SqlExecutorWithTx, store cac
 		//Mutex is free, continue
 	}
 
+	// Check the concurrent runs limit of the application, the node run waits like for a mutex
+	if n.Context.ApplicationID != 0 {
+		reached, err := application.ConcurrentRunsReached(db, n.Context.ApplicationID, nr.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		if reached {
+			log.Debug("Noderun %s processed but not executed because of application %d concurrent runs limit", n.Name, n.Context.ApplicationID)
+			AddWorkflowRunInfo(wr, sdk.SpawnMsgNew(*sdk.MsgWorkflowNodeConcurrentRuns, n.Name, wr.Workflow.Applications[n.Context.ApplicationID].Name))
+			if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+				return nil, false, sdk.WrapError(err, "unable to update workflow run")
+			}
+			return report, true, nil
+		}
+	}
+
 	//Execute the node run !
 	r1, err := executeNodeRun(ctx, db, store, proj, nr)
 	if err != nil {
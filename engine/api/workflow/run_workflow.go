@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/permission"
 	"github.com/ovh/cds/engine/cache"
 	"github.com/ovh/cds/engine/gorpmapper"
@@ -103,11 +104,23 @@ func StartWorkflowRun(ctx context.Context, db gorpmapper.SqlExecutorWithTx, stor
 		AddWorkflowRunInfo(wr, msg.ToSpawnMsg())
 	}
 
+	// Lock the applications before the first write so concurrent runs lock them in the same order
+	if err := lockConcurrentRuns(ctx, db, wr); err != nil {
+		return report, err
+	}
+
 	wr.Status = sdk.StatusWaiting
 	if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
 		return report, err
 	}
 
+	now := time.Now()
+	for appID := range wr.Workflow.Applications {
+		if err := application.UpdateLastUsed(db, appID, now); err != nil {
+			return report, err
+		}
+	}
+
 	if opts.Hook != nil {
 		// Run from HOOK
 		r1, err := runFromHook(ctx, db, store, proj, wr, opts.Hook, asCodeInfos)
@@ -74,7 +74,7 @@ func TestPull(t *testing.T) {
 	app := &sdk.Application{
 		Name: sdk.RandomString(10),
 	}
-	test.NoError(t, application.Insert(db, *proj, app))
+	test.NoError(t, application.Insert(context.TODO(), db, *proj, app))
 
 	//Environment
 	envName := sdk.RandomString(10)
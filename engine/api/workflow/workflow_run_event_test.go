@@ -46,9 +46,10 @@ func TestResyncCommitStatusNotifDisabled(t *testing.T) {
 		VCSServer:          "gerrit",
 		RepositoryStrategy: sdk.RepositoryStrategy{
 			ConnectionType: "ssh",
+			SSHKey:         "proj-ssh",
 		},
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	tr := true
@@ -142,9 +143,10 @@ func TestResyncCommitStatusSetStatus(t *testing.T) {
 		VCSServer:          "gerrit",
 		RepositoryStrategy: sdk.RepositoryStrategy{
 			ConnectionType: "ssh",
+			SSHKey:         "proj-ssh",
 		},
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	tr := true
@@ -245,9 +247,10 @@ func TestResyncCommitStatusCommentPR(t *testing.T) {
 		VCSServer:          "gerrit",
 		RepositoryStrategy: sdk.RepositoryStrategy{
 			ConnectionType: "ssh",
+			SSHKey:         "proj-ssh",
 		},
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	tr := true
@@ -362,9 +365,10 @@ func TestResyncCommitStatusCommentPRNotTerminated(t *testing.T) {
 		VCSServer:          "gerrit",
 		RepositoryStrategy: sdk.RepositoryStrategy{
 			ConnectionType: "ssh",
+			SSHKey:         "proj-ssh",
 		},
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	tr := true
@@ -470,9 +474,10 @@ func TestResyncCommitStatusCommitCache(t *testing.T) {
 		VCSServer:          "gerrit",
 		RepositoryStrategy: sdk.RepositoryStrategy{
 			ConnectionType: "ssh",
+			SSHKey:         "proj-ssh",
 		},
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	tr := true
@@ -469,7 +469,7 @@ func createApplication(t *testing.T, db gorpmapper.SqlExecutorWithTx, api *API,
 		RepositoryFullname: "foo/myrepo",
 		VCSServer:          "github",
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 	return &app
 }
@@ -260,7 +260,7 @@ version: v1.0`),
 		VCSServer:          "github",
 		RepositoryFullname: "myrepo",
 	}
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	require.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	env := sdk.Environment{
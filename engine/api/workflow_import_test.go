@@ -732,7 +732,7 @@ func Test_getWorkflowPushHandler(t *testing.T) {
 	app := &sdk.Application{
 		Name: appName,
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -742,7 +742,7 @@ func Test_getWorkflowPushHandler(t *testing.T) {
 		Type:  sdk.StringVariable,
 	}
 
-	test.NoError(t, application.InsertVariable(db, app.ID, &v1, u))
+	test.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &v1, u))
 
 	v2 := sdk.ApplicationVariable{
 		Name:  "var2",
@@ -750,7 +750,7 @@ func Test_getWorkflowPushHandler(t *testing.T) {
 		Type:  sdk.SecretVariable,
 	}
 
-	test.NoError(t, application.InsertVariable(db, app.ID, &v2, u))
+	test.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &v2, u))
 
 	//Insert ssh and gpg keys
 	k := &sdk.ApplicationKey{
@@ -765,7 +765,7 @@ func Test_getWorkflowPushHandler(t *testing.T) {
 	k.Public = kpgp.Public
 	k.Private = kpgp.Private
 	k.KeyID = kpgp.KeyID
-	test.NoError(t, application.InsertKey(db, k))
+	test.NoError(t, application.InsertKey(context.TODO(), db, k))
 
 	k2 := &sdk.ApplicationKey{
 		Name:          "app-mykey-ssh",
@@ -778,7 +778,7 @@ func Test_getWorkflowPushHandler(t *testing.T) {
 	k2.Public = kssh.Public
 	k2.Private = kssh.Private
 	k2.KeyID = kssh.KeyID
-	test.NoError(t, application.InsertKey(db, k2))
+	test.NoError(t, application.InsertKey(context.TODO(), db, k2))
 
 	w := sdk.Workflow{
 		Name:       "test_1",
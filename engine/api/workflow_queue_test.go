@@ -109,7 +109,7 @@ func testRunWorkflow(t *testing.T, api *API, router *Router) testRunWorkflowCtx
 	app := &sdk.Application{
 		Name: "app-" + sdk.RandomString(10),
 	}
-	if err := application.Insert(db, *proj, app); err != nil {
+	if err := application.Insert(context.TODO(), db, *proj, app); err != nil {
 		t.Fatal(err)
 	}
 
@@ -128,7 +128,7 @@ func testRunWorkflow(t *testing.T, api *API, router *Router) testRunWorkflowCtx
 	k.Private = pgpK.Private
 	k.KeyID = pgpK.KeyID
 
-	if err := application.InsertKey(db, k); err != nil {
+	if err := application.InsertKey(context.TODO(), db, k); err != nil {
 		t.Fatal(err)
 	}
 
@@ -1026,7 +1026,7 @@ func TestWorkerPrivateKey(t *testing.T) {
 		ProjectID: proj.ID,
 		Name:      sdk.RandomString(10),
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	// Create workflow
 	w := sdk.Workflow{
@@ -1138,7 +1138,7 @@ func TestPostVulnerabilityReportHandler(t *testing.T) {
 		ProjectID: proj.ID,
 		Name:      sdk.RandomString(10),
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	// Create workflow
 	w := sdk.Workflow{
@@ -1291,7 +1291,7 @@ func TestInsertNewCodeCoverageReport(t *testing.T) {
 		RepositoryFullname: "foo/bar",
 		VCSServer:          "repoManServ",
 	}
-	assert.NoError(t, application.Insert(db, *proj, &app))
+	assert.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	assert.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	// Create workflow
@@ -700,7 +700,7 @@ func Test_getWorkflowNodeRunHandler(t *testing.T) {
 		ProjectID: proj.ID,
 		Name:      "app",
 	}
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 
 	//First pipeline
 	pip := sdk.Pipeline{
@@ -990,10 +990,10 @@ func Test_postWorkflowRunHandler(t *testing.T) {
 			},
 		},
 	}
-	require.NoError(t, application.Insert(db, *proj, &app))
-	require.NoError(t, application.InsertVariable(db, app.ID, &app.Variables[0], u))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
+	require.NoError(t, application.InsertVariable(context.TODO(), db, app.ID, &app.Variables[0], u))
 	app.Keys[0].ApplicationID = app.ID
-	require.NoError(t, application.InsertKey(db, &app.Keys[0]))
+	require.NoError(t, application.InsertKey(context.TODO(), db, &app.Keys[0]))
 	require.NoError(t, application.SetDeploymentStrategy(db, proj.ID, app.ID, modelIntegration.ID, projInt.Name, app.DeploymentStrategies[projInt.Name]))
 
 	env := sdk.Environment{
@@ -1261,9 +1261,10 @@ func Test_postWorkflowRunAsyncFailedHandler(t *testing.T) {
 		VCSServer:          "github",
 		RepositoryStrategy: sdk.RepositoryStrategy{
 			ConnectionType: "ssh",
+			SSHKey:         "proj-ssh",
 		},
 	}
-	require.NoError(t, application.Insert(db, *proj, &app))
+	require.NoError(t, application.Insert(context.TODO(), db, *proj, &app))
 	require.NoError(t, repositoriesmanager.InsertForApplication(db, &app))
 
 	w := sdk.Workflow{
@@ -2188,6 +2189,118 @@ func Test_postWorkflowRunHandlerMutexRelease(t *testing.T) {
 	}
 }
 
+func Test_postWorkflowRunHandlerConcurrentRunsLimit(t *testing.T) {
+	api, db, router := newTestAPI(t)
+
+	u, jwt := assets.InsertAdminUser(t, db)
+
+	// Init test pipeline with one stage and one job
+	projKey := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, projKey, projKey)
+	pip := sdk.Pipeline{ProjectID: proj.ID, ProjectKey: proj.Key, Name: sdk.RandomString(10)}
+	require.NoError(t, pipeline.InsertPipeline(api.mustDB(), &pip))
+	stage := sdk.Stage{PipelineID: pip.ID, Name: sdk.RandomString(10), Enabled: true}
+	require.NoError(t, pipeline.InsertStage(api.mustDB(), &stage))
+	job := &sdk.Job{Enabled: true, Action: sdk.Action{Enabled: true}}
+	require.NoError(t, pipeline.InsertJob(api.mustDB(), job, stage.ID, &pip))
+
+	// Two workflows share an application that runs one node at a time
+	app := assets.InsertTestApplication(t, db, proj, sdk.Application{Name: sdk.RandomString(10)})
+	require.NoError(t, application.SetMaxConcurrentRuns(db, app.ID, 1))
+	var wkfs []sdk.Workflow
+	for i := 0; i < 2; i++ {
+		wkf := sdk.Workflow{
+			ProjectID:  proj.ID,
+			ProjectKey: proj.Key,
+			Name:       sdk.RandomString(10),
+			WorkflowData: sdk.WorkflowData{
+				Node: sdk.Node{
+					Name: "root",
+					Type: sdk.NodeTypePipeline,
+					Context: &sdk.NodeContext{
+						PipelineID:    pip.ID,
+						ApplicationID: app.ID,
+					},
+				},
+			},
+		}
+		require.NoError(t, workflow.Insert(context.TODO(), db, api.Cache, *proj, &wkf))
+		wkfs = append(wkfs, wkf)
+	}
+
+	waitRun := func(wkf sdk.Workflow, status string) sdk.WorkflowRun {
+		for try := 0; try <= 10; try++ {
+			uri := router.GetRoute("GET", api.getWorkflowRunHandler, map[string]string{
+				"key":              proj.Key,
+				"permWorkflowName": wkf.Name,
+				"number":           "1",
+			})
+			req := assets.NewAuthentifiedRequest(t, u, jwt, "GET", uri, nil)
+			rec := httptest.NewRecorder()
+			router.Mux.ServeHTTP(rec, req)
+			require.Equal(t, 200, rec.Code)
+
+			var wkfRun sdk.WorkflowRun
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &wkfRun))
+			if wkfRun.Status == status {
+				return wkfRun
+			}
+			t.Logf("Workflow run status: %s", wkfRun.Status)
+			time.Sleep(500 * time.Millisecond)
+		}
+		t.Fatalf("Maximum attempts reached on getWorkflowRunHandler for workflow %s", wkf.Name)
+		return sdk.WorkflowRun{}
+	}
+
+	for _, wkf := range wkfs {
+		uri := router.GetRoute("POST", api.postWorkflowRunHandler, map[string]string{
+			"key":              proj.Key,
+			"permWorkflowName": wkf.Name,
+		})
+		require.NotEmpty(t, uri)
+		req := assets.NewAuthentifiedRequest(t, u, jwt, "POST", uri, sdk.WorkflowRunPostHandlerOption{})
+		rec := httptest.NewRecorder()
+		router.Mux.ServeHTTP(rec, req)
+		require.Equal(t, 202, rec.Code)
+
+		lastRun, err := workflow.LoadLastRun(api.mustDB(), proj.Key, wkf.Name, workflow.LoadRunOptions{})
+		require.NoError(t, err)
+		waitCraftinWorkflow(t, api, db, lastRun.ID)
+		waitRun(wkf, sdk.StatusBuilding)
+	}
+
+	wkfRun1 := waitRun(wkfs[0], sdk.StatusBuilding)
+	require.Equal(t, sdk.StatusWaiting, wkfRun1.RootRun().Stages[0].Status)
+
+	// The second run reached the limit, its node run is waiting without error
+	wkfRun2 := waitRun(wkfs[1], sdk.StatusBuilding)
+	require.Equal(t, "", wkfRun2.RootRun().Stages[0].Status)
+	require.Equal(t, 2, len(wkfRun2.Infos))
+	require.Equal(t, sdk.MsgWorkflowNodeConcurrentRuns.ID, wkfRun2.Infos[1].Message.ID)
+
+	// Stopping the first run starts the second one
+	uri := router.GetRoute("POST", api.stopWorkflowRunHandler, map[string]string{
+		"key":              proj.Key,
+		"permWorkflowName": wkfs[0].Name,
+		"number":           "1",
+	})
+	require.NotEmpty(t, uri)
+	req := assets.NewAuthentifiedRequest(t, u, jwt, "POST", uri, nil)
+	rec := httptest.NewRecorder()
+	router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+	waitRun(wkfs[0], sdk.StatusStopped)
+
+	for try := 0; ; try++ {
+		require.True(t, try <= 10, "the second run should have been started")
+		wkfRun2 = waitRun(wkfs[1], sdk.StatusBuilding)
+		if wkfRun2.RootRun().Stages[0].Status == sdk.StatusWaiting {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
 func Test_postWorkflowRunHandlerHook(t *testing.T) {
 	api, db, router := newTestAPI(t)
 
@@ -2,6 +2,7 @@ package gorpmapper
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -38,6 +39,9 @@ func (m *Mapper) Encrypt(src interface{}, dst *[]byte, extra []interface{}) erro
 	return nil
 }
 
+// ErrDecryption is the cause of the errors returned when an encrypted content can't be decrypted.
+var ErrDecryption = errors.New("unable to decrypt content")
+
 func (m *Mapper) Decrypt(src []byte, dest interface{}, extra []interface{}) error {
 	t := reflect.TypeOf(dest)
 	if t.Kind() != reflect.Ptr {
@@ -52,7 +56,7 @@ func (m *Mapper) Decrypt(src []byte, dest interface{}, extra []interface{}) erro
 
 	clearContent, err := m.encryptionKey.Decrypt(src, extrabytes...)
 	if err != nil {
-		return sdk.WithStack(fmt.Errorf("unable to decrypt content: %v", err))
+		return sdk.WrapError(ErrDecryption, "%v", err)
 	}
 
 	return json.Unmarshal(clearContent, dest)
@@ -52,6 +52,11 @@ func (m *Mapper) UpdateColumnsAndSign(ctx context.Context, db gorp.SqlExecutor,
 	return sdk.WithStack(m.dbSign(ctx, db, i))
 }
 
+// Sign only updates the signature of a data in database, other columns are not written.
+func (m *Mapper) Sign(ctx context.Context, db gorp.SqlExecutor, i Canonicaller) error {
+	return sdk.WithStack(m.dbSign(ctx, db, i))
+}
+
 // CheckSignature return true if a given signature is valid for given object.
 func (m *Mapper) CheckSignature(i Canonicaller, sig []byte) (bool, error) {
 	var canonicalForms = i.Canonical()
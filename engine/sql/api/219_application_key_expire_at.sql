@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE "application_key" ADD COLUMN IF NOT EXISTS expire_at TIMESTAMP WITH TIME ZONE;
+SELECT create_index('application_key', 'IDX_APPLICATION_KEY_EXPIRE_AT', 'expire_at');
+
+-- +migrate Down
+ALTER TABLE "application_key" DROP COLUMN expire_at;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE "application_changelog" (
+    seq BIGSERIAL PRIMARY KEY,
+    application_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    created TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP,
+    data JSONB
+);
+SELECT create_index('application_changelog', 'IDX_APPLICATION_CHANGELOG_APPLICATION_ID', 'application_id,created');
+
+-- +migrate Down
+DROP TABLE IF EXISTS "application_changelog";
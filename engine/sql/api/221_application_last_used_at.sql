@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
+SELECT create_index('application', 'IDX_APPLICATION_LAST_USED_AT', 'project_id,last_used_at');
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS last_used_at;
@@ -0,0 +1,5 @@
+-- +migrate Up
+SELECT create_index('application', 'IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED', 'project_id,last_modified');
+
+-- +migrate Down
+DROP INDEX IF EXISTS IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED;
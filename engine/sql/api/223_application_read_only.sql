@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS read_only BOOLEAN NOT NULL DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS read_only;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS retention_days INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS retention_days;
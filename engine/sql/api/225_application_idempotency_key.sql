@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "application_idempotency_key" (
+    "project_id" BIGINT NOT NULL,
+    "idem_key" VARCHAR(256) NOT NULL,
+    "application_id" BIGINT NOT NULL,
+    "created" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("project_id", "idem_key")
+);
+SELECT create_foreign_key_idx_cascade('FK_APPLICATION_IDEMPOTENCY_KEY_APPLICATION', 'application_idempotency_key', 'application', 'application_id', 'id');
+
+-- +migrate Down
+DROP TABLE IF EXISTS "application_idempotency_key";
@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_application_repo_fullname_prefix ON "application" (project_id, repo_fullname text_pattern_ops);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_application_repo_fullname_prefix;
@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "application_repository" (
+    "application_id" BIGINT NOT NULL,
+    "repo_fullname" VARCHAR(256) NOT NULL,
+    PRIMARY KEY ("application_id", "repo_fullname")
+);
+SELECT create_foreign_key_idx_cascade('FK_APPLICATION_REPOSITORY_APPLICATION', 'application_repository', 'application', 'application_id', 'id');
+SELECT create_index('application_repository', 'IDX_APPLICATION_REPOSITORY_REPO_FULLNAME', 'repo_fullname');
+
+-- +migrate Down
+DROP TABLE IF EXISTS "application_repository";
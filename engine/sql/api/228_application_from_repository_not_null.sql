@@ -0,0 +1,7 @@
+-- +migrate Up
+UPDATE "application" SET from_repository = '' WHERE from_repository IS NULL;
+ALTER TABLE "application" ALTER COLUMN from_repository SET DEFAULT '';
+ALTER TABLE "application" ALTER COLUMN from_repository SET NOT NULL;
+
+-- +migrate Down
+ALTER TABLE "application" ALTER COLUMN from_repository DROP NOT NULL;
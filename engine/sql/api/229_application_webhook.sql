@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "application_webhook" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "application_id" BIGINT NOT NULL,
+    "url" TEXT NOT NULL,
+    "events" JSONB,
+    "cipher_signing_key" BYTEA,
+    "created" TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP,
+    "sig" BYTEA,
+    "signer" TEXT
+);
+SELECT create_unique_index('application_webhook', 'IDX_APPLICATION_WEBHOOK_URL', 'application_id,url');
+SELECT create_foreign_key_idx_cascade('FK_APPLICATION_WEBHOOK_APPLICATION', 'application_webhook', 'application', 'application_id', 'id');
+
+-- +migrate Down
+DROP TABLE IF EXISTS "application_webhook";
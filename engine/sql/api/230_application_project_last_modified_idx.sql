@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_application_project_last_modified ON "application" (project_id, last_modified DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_application_project_last_modified;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS vcs_connection_type VARCHAR(64);
+SELECT create_index('application', 'IDX_APPLICATION_VCS_CONNECTION_TYPE', 'project_id,vcs_connection_type');
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS vcs_connection_type;
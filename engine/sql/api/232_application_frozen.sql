@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS frozen;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- The unique index on (application_id, lower(var_name)) is created concurrently by the ApplicationVariableNameIndex
+-- API migration, once variables that only differ by case have been cleaned.
+SELECT 1;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_application_variable_lower_name;
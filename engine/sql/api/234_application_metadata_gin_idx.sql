@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_application_metadata ON "application" USING GIN (metadata jsonb_path_ops);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_application_metadata;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMP WITH TIME ZONE;
+SELECT create_index('application', 'IDX_APPLICATION_LAST_ACCESSED_AT', 'project_id,last_accessed_at');
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS last_accessed_at;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS max_concurrent_runs INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS max_concurrent_runs;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS deploy_allowlist TEXT[];
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS deploy_allowlist;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS color VARCHAR(32) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS color;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE "application_changelog" ADD COLUMN IF NOT EXISTS xid BIGINT NOT NULL DEFAULT 0;
+SELECT create_index('application_changelog', 'IDX_APPLICATION_CHANGELOG_XID_SEQ', 'xid,seq');
+
+-- +migrate Down
+ALTER TABLE "application_changelog" DROP COLUMN IF EXISTS xid;
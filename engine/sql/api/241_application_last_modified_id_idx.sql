@@ -0,0 +1,7 @@
+-- +migrate Up
+SELECT create_index('application', 'IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED_ID', 'project_id,last_modified,id');
+DROP INDEX IF EXISTS IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED;
+
+-- +migrate Down
+SELECT create_index('application', 'IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED', 'project_id,last_modified');
+DROP INDEX IF EXISTS IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED_ID;
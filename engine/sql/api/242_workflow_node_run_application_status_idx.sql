@@ -0,0 +1,5 @@
+-- +migrate Up
+SELECT create_index('workflow_node_run', 'IDX_WORKFLOW_NODE_RUN_APPLICATION_STATUS', 'application_id,status');
+
+-- +migrate Down
+DROP INDEX IF EXISTS IDX_WORKFLOW_NODE_RUN_APPLICATION_STATUS;
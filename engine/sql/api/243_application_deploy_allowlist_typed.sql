@@ -0,0 +1,30 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS deploy_allowlist_environments TEXT[];
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS deploy_allowlist_integrations TEXT[];
+
+-- Names that are neither an environment nor an integration of the project stay in both lists to keep restricting
+UPDATE application SET
+    deploy_allowlist_environments = NULLIF(ARRAY(
+        SELECT name FROM unnest(application.deploy_allowlist) AS name
+        WHERE name IN (SELECT environment.name FROM environment WHERE environment.project_id = application.project_id)
+        OR name NOT IN (SELECT project_integration.name FROM project_integration WHERE project_integration.project_id = application.project_id)
+        ORDER BY name
+    ), '{}'),
+    deploy_allowlist_integrations = NULLIF(ARRAY(
+        SELECT name FROM unnest(application.deploy_allowlist) AS name
+        WHERE name IN (SELECT project_integration.name FROM project_integration WHERE project_integration.project_id = application.project_id)
+        OR name NOT IN (SELECT environment.name FROM environment WHERE environment.project_id = application.project_id)
+        ORDER BY name
+    ), '{}')
+WHERE deploy_allowlist IS NOT NULL;
+
+ALTER TABLE "application" DROP COLUMN IF EXISTS deploy_allowlist;
+
+-- +migrate Down
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS deploy_allowlist TEXT[];
+UPDATE application SET deploy_allowlist = NULLIF(ARRAY(
+    SELECT DISTINCT name FROM unnest(COALESCE(deploy_allowlist_environments, '{}') || COALESCE(deploy_allowlist_integrations, '{}')) AS name
+    ORDER BY name
+), '{}');
+ALTER TABLE "application" DROP COLUMN IF EXISTS deploy_allowlist_environments;
+ALTER TABLE "application" DROP COLUMN IF EXISTS deploy_allowlist_integrations;
@@ -0,0 +1,5 @@
+-- +migrate Up
+SELECT create_index('application_changelog', 'IDX_APPLICATION_CHANGELOG_CREATED', 'created');
+
+-- +migrate Down
+DROP INDEX IF EXISTS IDX_APPLICATION_CHANGELOG_CREATED;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "application" ADD COLUMN IF NOT EXISTS vcs_password_set BOOLEAN;
+
+-- +migrate Down
+ALTER TABLE "application" DROP COLUMN IF EXISTS vcs_password_set;
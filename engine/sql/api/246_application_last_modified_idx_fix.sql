@@ -0,0 +1,7 @@
+-- +migrate Up
+SELECT create_index('application', 'IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED', 'project_id,last_modified');
+DROP INDEX IF EXISTS idx_application_project_last_modified;
+
+-- +migrate Down
+CREATE INDEX IF NOT EXISTS idx_application_project_last_modified ON "application" (project_id, last_modified DESC);
+DROP INDEX IF EXISTS IDX_APPLICATION_PROJECT_ID_LAST_MODIFIED;
@@ -1,8 +1,12 @@
 package sdk
 
 import (
+	"encoding/json"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // Repository structs contains all needed information about a single repository
@@ -11,6 +15,44 @@ type Repository struct {
 	Hook bool
 }
 
+// ApplicationReservedNames are the names that can't be used for an application because they
+// collide with routes or cli commands. Comparison is case insensitive.
+var ApplicationReservedNames = []string{".", "..", "new", "all", "favorites"}
+
+// IsApplicationReservedName returns true if given name is in ApplicationReservedNames.
+func IsApplicationReservedName(name string) bool {
+	for _, n := range ApplicationReservedNames {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplicationDescriptionMaxLength is the maximum length in bytes of an application description.
+var ApplicationDescriptionMaxLength = 2048
+
+// RemoveControlCharacters returns given string without control characters except new lines and tabs.
+func RemoveControlCharacters(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// ApplicationColorPalette are the named colors that can be used for an application in addition to hex codes.
+var ApplicationColorPalette = []string{"red", "orange", "yellow", "green", "teal", "blue", "purple", "pink", "brown", "grey"}
+
+var applicationColorHexRegex = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+
+// IsValidApplicationColor returns true if given color is a lower case hex code of 3 or 6 digits, or a color
+// of ApplicationColorPalette.
+func IsValidApplicationColor(color string) bool {
+	return applicationColorHexRegex.MatchString(color) || IsInArray(color, ApplicationColorPalette)
+}
+
 // Application represent an application in a project
 type Application struct {
 	ID                   int64                        `json:"id" db:"id"`
@@ -31,6 +73,13 @@ type Application struct {
 	DeploymentStrategies map[string]IntegrationConfig `json:"deployment_strategies,omitempty" db:"-" cli:"-"`
 	Vulnerabilities      []Vulnerability              `json:"vulnerabilities,omitempty" db:"-" cli:"-"`
 	FromRepository       string                       `json:"from_repository,omitempty" db:"from_repository" cli:"-"`
+	ReadOnly             bool                         `json:"read_only" db:"read_only" cli:"-"`
+	Frozen               bool                         `json:"frozen" db:"frozen" cli:"-"`
+	RetentionDays        int64                        `json:"retention_days,omitempty" db:"retention_days" cli:"-"`
+	MaxConcurrentRuns    int64                        `json:"max_concurrent_runs,omitempty" db:"max_concurrent_runs" cli:"-"`
+	Color                string                       `json:"color,omitempty" db:"color" cli:"-"`
+	Webhooks             []ApplicationWebhook         `json:"webhooks,omitempty" db:"-" cli:"-"`
+	Pipelines            []Pipeline                   `json:"pipelines,omitempty" db:"-" cli:"-"`
 	// aggregate
 	WorkflowAscodeHolder *Workflow `json:"workflow_ascode_holder,omitempty" cli:"-" db:"-"`
 }
@@ -38,7 +87,26 @@ type Application struct {
 // IsValid returns error if the application is not valid.
 func (app Application) IsValid() error {
 	if !NamePatternRegex.MatchString(app.Name) {
-		return NewErrorFrom(ErrInvalidName, "application name should match pattern %s", NamePattern)
+		return NewErrorFrom(ErrInvalidName, "application name %q should match pattern %s", app.Name, NamePattern)
+	}
+	if IsApplicationReservedName(app.Name) {
+		return NewErrorFrom(ErrInvalidName, "application name %q is reserved", app.Name)
+	}
+
+	if len(app.Description) > ApplicationDescriptionMaxLength {
+		return NewErrorFrom(ErrWrongRequest, "application description should not exceed %d characters (got %d)", ApplicationDescriptionMaxLength, len(app.Description))
+	}
+
+	if app.RetentionDays < 0 {
+		return NewErrorFrom(ErrWrongRequest, "application retention days should not be negative")
+	}
+
+	if app.MaxConcurrentRuns < 0 {
+		return NewErrorFrom(ErrWrongRequest, "application max concurrent runs should not be negative")
+	}
+
+	if app.Color != "" && !IsValidApplicationColor(app.Color) {
+		return NewErrorFrom(ErrWrongRequest, "application color %q should be a hex code such as #3c8dbc or one of %s", app.Color, strings.Join(ApplicationColorPalette, ", "))
 	}
 
 	if app.Icon != "" {
@@ -50,9 +118,34 @@ func (app Application) IsValid() error {
 		}
 	}
 
+	if app.RepositoryStrategy.ConnectionType == "ssh" && app.RepositoryStrategy.SSHKey == "" && app.RepositoryStrategy.SSHKeyContent == "" {
+		return NewErrorFrom(ErrInvalidApplicationRepoStrategy, "application %s: connection type ssh requires an ssh key", app.Name)
+	}
+
+	if ref := app.RepositoryStrategy.PasswordRef; ref != "" {
+		if !SecretReferenceRegex.MatchString(ref) {
+			return NewErrorFrom(ErrInvalidApplicationRepoStrategy, "application %s: password reference %q should match pattern %s", app.Name, ref, SecretReferencePattern)
+		}
+		if app.RepositoryStrategy.ConnectionType != "https" {
+			return NewErrorFrom(ErrInvalidApplicationRepoStrategy, "application %s: password reference requires connection type https", app.Name)
+		}
+	}
+
 	return nil
 }
 
+// MarshalJSON masks the vcs strategy password and ssh key content so an application loaded with
+// clear secrets can't be serialized with them.
+func (app Application) MarshalJSON() ([]byte, error) {
+	type application Application
+	a := application(app)
+	if a.RepositoryStrategy.Password != "" {
+		a.RepositoryStrategy.Password = PasswordPlaceholder
+	}
+	a.RepositoryStrategy.SSHKeyContent = ""
+	return json.Marshal(a)
+}
+
 // SSHKeys returns the slice of ssh key for an application
 func (app Application) SSHKeys() []ApplicationKey {
 	keys := []ApplicationKey{}
@@ -85,6 +178,123 @@ type RepositoryStrategy struct {
 	Branch         string `json:"branch,omitempty"`
 	DefaultBranch  string `json:"default_branch,omitempty"`
 	PGPKey         string `json:"pgp_key"`
+	// PasswordRef references an external secret used as password instead of storing it, see SecretReferencePattern.
+	PasswordRef string `json:"password_ref,omitempty"`
+}
+
+// SecretReferencePattern is the format of a reference to an external secret, a provider and a path such as
+// vault://secret/my-team/my-repo.
+const SecretReferencePattern = `^[a-z][a-z0-9-]*://[a-zA-Z0-9_.-]+(/[a-zA-Z0-9_.-]+)*$`
+
+// SecretReferenceRegex is the compiled SecretReferencePattern.
+var SecretReferenceRegex = regexp.MustCompile(SecretReferencePattern)
+
+// UnmarshalJSON custom to upgrade repository strategies stored by older versions of CDS,
+// that used camel case keys. Current keys have priority over legacy ones. An upgraded strategy
+// is stored with current keys on next save.
+func (r *RepositoryStrategy) UnmarshalJSON(data []byte) error {
+	type current RepositoryStrategy
+	var tmp current
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	*r = RepositoryStrategy(tmp)
+
+	var legacy struct {
+		ConnectionType string `json:"connectionType"`
+		SSHKey         string `json:"sshKey"`
+		SSHKeyContent  string `json:"sshKeyContent"`
+		User           string `json:"username"`
+		DefaultBranch  string `json:"defaultBranch"`
+		PGPKey         string `json:"pgpKey"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		current *string
+		legacy  string
+	}{
+		{&r.ConnectionType, legacy.ConnectionType},
+		{&r.SSHKey, legacy.SSHKey},
+		{&r.SSHKeyContent, legacy.SSHKeyContent},
+		{&r.User, legacy.User},
+		{&r.DefaultBranch, legacy.DefaultBranch},
+		{&r.PGPKey, legacy.PGPKey},
+	} {
+		if *f.current == "" {
+			*f.current = f.legacy
+		}
+	}
+	return nil
+}
+
+// Application changelog types.
+const (
+	ApplicationChangeInsert = "insert"
+	ApplicationChangeUpdate = "update"
+	ApplicationChangeDelete = "delete"
+)
+
+// ApplicationChange represents an entry of the applications changelog.
+// Application is the state after the change, with secrets masked, it is nil for a delete.
+type ApplicationChange struct {
+	Seq           int64        `json:"seq" db:"seq"`
+	Xid           int64        `json:"xid" db:"xid"`
+	ApplicationID int64        `json:"application_id" db:"application_id"`
+	ProjectID     int64        `json:"project_id" db:"project_id"`
+	Type          string       `json:"type" db:"type"`
+	Created       time.Time    `json:"created" db:"created"`
+	Application   *Application `json:"application,omitempty" db:"-"`
+}
+
+// Cursor returns the position of the change in the applications changelog.
+func (c ApplicationChange) Cursor() ApplicationChangeCursor {
+	return ApplicationChangeCursor{Xid: c.Xid, Seq: c.Seq}
+}
+
+// ApplicationChangeCursor is a position in the applications changelog, changes are ordered by the id of
+// the transaction that wrote them then by sequence.
+type ApplicationChangeCursor struct {
+	Xid int64 `json:"xid" db:"xid"`
+	Seq int64 `json:"seq" db:"seq"`
+}
+
+// ApplicationDeployAllowlist are the environment and integration names that workflows can deploy an application to.
+// An empty list doesn't restrict its kind of target.
+type ApplicationDeployAllowlist struct {
+	Environments []string `json:"environments,omitempty"`
+	Integrations []string `json:"integrations,omitempty"`
+}
+
+// ApplicationWebhook is an outbound endpoint notified of the changes of an application.
+// Events are application changelog types, the signing key is stored encrypted.
+type ApplicationWebhook struct {
+	ID            int64       `json:"id" db:"id"`
+	ApplicationID int64       `json:"application_id" db:"application_id"`
+	URL           string      `json:"url" db:"url"`
+	Events        StringSlice `json:"events" db:"events"`
+	SigningKey    string      `json:"signing_key,omitempty" db:"cipher_signing_key" gorpmapping:"encrypted,ID,ApplicationID"`
+	Created       time.Time   `json:"created" db:"created"`
+}
+
+// IsValid returns error if the webhook is not valid.
+func (w ApplicationWebhook) IsValid() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewErrorFrom(ErrWrongRequest, "invalid webhook url %q", w.URL)
+	}
+	if len(w.Events) == 0 {
+		return NewErrorFrom(ErrWrongRequest, "webhook %s should have at least one event", w.URL)
+	}
+	for _, e := range w.Events {
+		switch e {
+		case ApplicationChangeInsert, ApplicationChangeUpdate, ApplicationChangeDelete:
+		default:
+			return NewErrorFrom(ErrWrongRequest, "invalid webhook event %q", e)
+		}
+	}
+	return nil
 }
 
 // ApplicationVariableAudit represents an audit on an application variable
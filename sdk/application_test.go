@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationIsValid(t *testing.T) {
+	require.NoError(t, Application{Name: "my-app"}.IsValid())
+
+	err := Application{Name: "my app"}.IsValid()
+	require.True(t, ErrorIs(err, ErrInvalidName))
+
+	err = Application{Name: "my/app"}.IsValid()
+	require.True(t, ErrorIs(err, ErrInvalidName))
+
+	for _, n := range []string{"new", "All", "..", "favorites"} {
+		err = Application{Name: n}.IsValid()
+		require.True(t, ErrorIs(err, ErrInvalidName), n)
+		require.Contains(t, err.Error(), n)
+	}
+
+	err = Application{Name: "my-app", RepositoryStrategy: RepositoryStrategy{ConnectionType: "ssh"}}.IsValid()
+	require.True(t, ErrorIs(err, ErrInvalidApplicationRepoStrategy))
+
+	require.NoError(t, Application{Name: "my-app", RepositoryStrategy: RepositoryStrategy{ConnectionType: "ssh", SSHKey: "proj-ssh"}}.IsValid())
+	require.NoError(t, Application{Name: "my-app", RepositoryStrategy: RepositoryStrategy{ConnectionType: "ssh", SSHKeyContent: "content"}}.IsValid())
+	require.NoError(t, Application{Name: "my-app", RepositoryStrategy: RepositoryStrategy{ConnectionType: "https", User: "user"}}.IsValid())
+}
+
+func TestApplicationIsValidPasswordRef(t *testing.T) {
+	require.NoError(t, Application{Name: "my-app", RepositoryStrategy: RepositoryStrategy{ConnectionType: "https", PasswordRef: "vault://secret/my-team/my-repo"}}.IsValid())
+
+	for _, ref := range []string{"secret/my-repo", "vault://", "vault://secret//my-repo", "Vault://secret", "vault://secret/my repo"} {
+		err := Application{Name: "my-app", RepositoryStrategy: RepositoryStrategy{ConnectionType: "https", PasswordRef: ref}}.IsValid()
+		require.True(t, ErrorIs(err, ErrInvalidApplicationRepoStrategy), ref)
+	}
+
+	err := Application{Name: "my-app", RepositoryStrategy: RepositoryStrategy{ConnectionType: "ssh", SSHKey: "proj-ssh", PasswordRef: "vault://secret"}}.IsValid()
+	require.True(t, ErrorIs(err, ErrInvalidApplicationRepoStrategy))
+}
+
+func TestRepositoryStrategyUnmarshalJSON(t *testing.T) {
+	var r RepositoryStrategy
+	require.NoError(t, json.Unmarshal([]byte(`{"connection_type":"ssh","ssh_key":"proj-ssh","pgp_key":"proj-pgp","branch":"master"}`), &r))
+	require.Equal(t, RepositoryStrategy{ConnectionType: "ssh", SSHKey: "proj-ssh", PGPKey: "proj-pgp", Branch: "master"}, r)
+
+	// Legacy fixture
+	r = RepositoryStrategy{}
+	require.NoError(t, json.Unmarshal([]byte(`{"connectionType":"https","username":"user","password":"pwd","defaultBranch":"master","pgpKey":"proj-pgp"}`), &r))
+	require.Equal(t, RepositoryStrategy{ConnectionType: "https", User: "user", Password: "pwd", DefaultBranch: "master", PGPKey: "proj-pgp"}, r)
+
+	// Current keys have priority
+	r = RepositoryStrategy{}
+	require.NoError(t, json.Unmarshal([]byte(`{"connection_type":"ssh","connectionType":"https","ssh_key":"proj-ssh"}`), &r))
+	require.Equal(t, "ssh", r.ConnectionType)
+
+	// Saved with current keys
+	btes, err := json.Marshal(RepositoryStrategy{ConnectionType: "https", User: "user"})
+	require.NoError(t, err)
+	require.Contains(t, string(btes), `"connection_type":"https"`)
+}
+
+func TestApplicationIsValidDescription(t *testing.T) {
+	require.NoError(t, Application{Name: "my-app", Description: strings.Repeat("a", ApplicationDescriptionMaxLength)}.IsValid())
+
+	err := Application{Name: "my-app", Description: strings.Repeat("a", ApplicationDescriptionMaxLength+1)}.IsValid()
+	require.True(t, ErrorIs(err, ErrWrongRequest))
+	require.Contains(t, err.Error(), "2049")
+
+	require.Equal(t, "line 1\nline 2\ttab", RemoveControlCharacters("line 1\nline\x00 2\ttab\x1b"))
+}
+
+func TestApplicationMarshalJSONMasksSecrets(t *testing.T) {
+	app := Application{
+		Name: "my-app",
+		RepositoryStrategy: RepositoryStrategy{
+			ConnectionType: "ssh",
+			SSHKey:         "proj-ssh",
+			SSHKeyContent:  "my-private-key",
+			User:           "user",
+			Password:       "my-password",
+		},
+	}
+	for _, v := range []interface{}{app, &app, EventApplicationAdd{Application: app}, map[string]Application{"app": app}} {
+		btes, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.NotContains(t, string(btes), "my-password")
+		require.NotContains(t, string(btes), "my-private-key")
+		require.Contains(t, string(btes), PasswordPlaceholder)
+		require.Contains(t, string(btes), "proj-ssh")
+	}
+
+	// Application is not modified
+	require.Equal(t, "my-password", app.RepositoryStrategy.Password)
+
+	var res Application
+	btes, err := json.Marshal(Application{Name: "my-app"})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(btes, &res))
+	require.Empty(t, res.RepositoryStrategy.Password)
+}
+
+func TestApplicationIsValidColor(t *testing.T) {
+	for _, c := range []string{"", "#3c8dbc", "#fff", "blue", "grey"} {
+		require.NoError(t, Application{Name: "my-app", Color: c}.IsValid(), c)
+	}
+	for _, c := range []string{"3c8dbc", "#3C8DBC", "#3c8d", "#ggg", "Blue", "blue ", "rgb(0,0,0)"} {
+		err := Application{Name: "my-app", Color: c}.IsValid()
+		require.True(t, ErrorIs(err, ErrWrongRequest), c)
+	}
+}
@@ -8,14 +8,18 @@ import (
 	"github.com/ovh/cds/sdk"
 )
 
+// applicationWithSecrets is sent to the API to create or update an application with its vcs strategy
+// secrets that are masked when a sdk.Application is serialized.
+type applicationWithSecrets sdk.Application
+
 func (c *client) ApplicationCreate(key string, app *sdk.Application) error {
-	_, err := c.PostJSON(context.Background(), "/project/"+key+"/applications", app, nil)
+	_, err := c.PostJSON(context.Background(), "/project/"+key+"/applications", applicationWithSecrets(*app), nil)
 	return err
 }
 
 func (c *client) ApplicationUpdate(projectKey string, appName string, app *sdk.Application) error {
 	url := fmt.Sprintf("/project/%s/application/%s", url.QueryEscape(projectKey), url.QueryEscape(appName))
-	_, err := c.PutJSON(context.Background(), url, app, app)
+	_, err := c.PutJSON(context.Background(), url, applicationWithSecrets(*app), app)
 	return err
 }
 
@@ -45,6 +45,7 @@ type IDName struct {
 	Name        string  `json:"name" db:"name"`
 	Description string  `json:"description,omitempty" db:"description"`
 	Icon        string  `json:"icon,omitempty" db:"icon"`
+	Color       string  `json:"color,omitempty" db:"color"`
 	Labels      []Label `json:"labels,omitempty" db:"-"`
 }
 
@@ -3,6 +3,7 @@ package sdk
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type KeyType string
@@ -45,13 +46,14 @@ type ProjectKey struct {
 
 // ApplicationKey represent a key attach to an application
 type ApplicationKey struct {
-	ID            int64   `json:"id" db:"id" cli:"-"`
-	Name          string  `json:"name" db:"name" cli:"name"`
-	Public        string  `json:"public" db:"public" cli:"publickey"`
-	Private       string  `json:"private" db:"private" cli:"-" gorpmapping:"encrypted,ID,Name"`
-	KeyID         string  `json:"key_id" db:"key_id" cli:"-"`
-	Type          KeyType `json:"type" db:"type" cli:"type"`
-	ApplicationID int64   `json:"application_id" db:"application_id"`
+	ID            int64      `json:"id" db:"id" cli:"-"`
+	Name          string     `json:"name" db:"name" cli:"name"`
+	Public        string     `json:"public" db:"public" cli:"publickey"`
+	Private       string     `json:"private" db:"private" cli:"-" gorpmapping:"encrypted,ID,Name"`
+	KeyID         string     `json:"key_id" db:"key_id" cli:"-"`
+	Type          KeyType    `json:"type" db:"type" cli:"type"`
+	ApplicationID int64      `json:"application_id" db:"application_id"`
+	ExpireAt      *time.Time `json:"expire_at,omitempty" db:"expire_at" cli:"-"`
 }
 
 // EnvironmentKey represent a key attach to an environment
@@ -74,6 +74,7 @@ var (
 	MsgWorkflowNodeStop                     = &Message{"MsgWorkflowNodeStop", trad{FR: "Le pipeline a été arrété par %s", EN: "The pipeline has been stopped by %s"}, nil, RunInfoTypInfo}
 	MsgWorkflowNodeMutex                    = &Message{"MsgWorkflowNodeMutex", trad{FR: "Le pipeline %s est mis en attente tant qu'il est en cours sur un autre run", EN: "The pipeline %s is waiting while it's running on another run"}, nil, RunInfoTypInfo}
 	MsgWorkflowNodeMutexRelease             = &Message{"MsgWorkflowNodeMutexRelease", trad{FR: "Lancement du pipeline %s", EN: "Triggering pipeline %s"}, nil, RunInfoTypInfo}
+	MsgWorkflowNodeConcurrentRuns           = &Message{"MsgWorkflowNodeConcurrentRuns", trad{FR: "Le pipeline %s est mis en attente tant que l'application %s a atteint sa limite de runs simultanés", EN: "The pipeline %s is waiting while the application %s reached its limit of concurrent runs"}, nil, RunInfoTypInfo}
 	MsgWorkflowImportedUpdated              = &Message{"MsgWorkflowImportedUpdated", trad{FR: "Le workflow %s a été mis à jour", EN: "Workflow %s has been updated"}, nil, RunInfoTypInfo}
 	MsgWorkflowImportedInserted             = &Message{"MsgWorkflowImportedInserted", trad{FR: "Le workflow %s a été créé", EN: "Workflow %s has been created"}, nil, RunInfoTypInfo}
 	MsgSpawnInfoHatcheryCannotStartJob      = &Message{"MsgSpawnInfoHatcheryCannotStart", trad{FR: "Aucune hatchery n'a pu démarrer de worker respectant vos pré-requis de job, merci de les vérifier.", EN: "No hatchery can spawn a worker corresponding your job's requirements. Please check your job's requirements."}, nil, RunInfoTypeWarning}
@@ -148,6 +149,7 @@ var Messages = map[string]*Message{
 	MsgWorkflowNodeStop.ID:                     MsgWorkflowNodeStop,
 	MsgWorkflowNodeMutex.ID:                    MsgWorkflowNodeMutex,
 	MsgWorkflowNodeMutexRelease.ID:             MsgWorkflowNodeMutexRelease,
+	MsgWorkflowNodeConcurrentRuns.ID:           MsgWorkflowNodeConcurrentRuns,
 	MsgWorkflowImportedUpdated.ID:              MsgWorkflowImportedUpdated,
 	MsgWorkflowImportedInserted.ID:             MsgWorkflowImportedInserted,
 	MsgSpawnInfoHatcheryCannotStartJob.ID:      MsgSpawnInfoHatcheryCannotStartJob,
@@ -12,4 +12,7 @@ const (
 	ResponseTemplateGroupNameHeader = "X-Api-Template-Group-Name"
 	// ResponseTemplateSlugHeader is used as HTTP header
 	ResponseTemplateSlugHeader = "X-Api-Template-Slug"
+
+	// IdempotencyKeyHeader is used as HTTP header to make a creation request retryable
+	IdempotencyKeyHeader = "Idempotency-Key"
 )
@@ -77,6 +77,8 @@ type ApplicationVariable struct {
 	Value         string `json:"value" cli:"value"`
 	Type          string `json:"type" cli:"type"`
 	ApplicationID int64  `json:"application_id" cli:"-"`
+	// Undecryptable is set when the secret value of the variable could not be decrypted, its value is empty.
+	Undecryptable bool `json:"undecryptable,omitempty" cli:"-"`
 }
 
 type EnvironmentVariable struct {